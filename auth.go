@@ -0,0 +1,109 @@
+package gorest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Token is access token for bearer authentication
+type Token struct {
+	AccessToken string
+	// TokenType is type like `Bearer`, empty means `Bearer`
+	TokenType    string
+	RefreshToken string
+	// Expiry is expiration time, zero means never expires
+	Expiry time.Time
+}
+
+// Type returns token type for Authorization header
+func (t *Token) Type() string {
+	if t.TokenType == `` || strings.EqualFold(t.TokenType, `bearer`) {
+		return `Bearer`
+	}
+	return t.TokenType
+}
+
+// expired reports whether token expires within delta
+func (t *Token) expired(now time.Time, delta time.Duration) bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+	return !now.Add(delta).Before(t.Expiry)
+}
+
+// TokenSource returns token for bearer authentication
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenInvalidator is implemented by TokenSource which caches token.
+// when server responds 401, token is invalidated and request is sent once again with new token.
+type TokenInvalidator interface {
+	Invalidate(accessToken string)
+}
+
+// staticTokenSource always returns same token
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticTokenSource returns TokenSource which always returns the access token
+func StaticTokenSource(accessToken string) TokenSource {
+	return &staticTokenSource{token: &Token{AccessToken: accessToken}}
+}
+
+func (s *staticTokenSource) Token(context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+// setAuthorization sets basic auth, bearer token or token from token source
+func (cli *client) setAuthorization(req *http.Request) error {
+	if cli.username != nil && cli.password != nil {
+		req.SetBasicAuth(*cli.username, *cli.password)
+	}
+	if cli.bearerToken != nil {
+		req.Header.Set(`Authorization`, `Bearer `+*cli.bearerToken)
+	}
+	if cli.tokenSource != nil {
+		token, err := cli.tokenSource.Token(req.Context())
+		if err != nil {
+			return err
+		}
+		req.Header.Set(`Authorization`, token.Type()+` `+token.AccessToken)
+	}
+	return nil
+}
+
+// retryUnauthorized sends request once again with new token, if server responds 401
+func retryUnauthorized(next Doer, source TokenSource) Doer {
+	invalidator, ok := source.(TokenInvalidator)
+	if !ok {
+		return next
+	}
+
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := next.Do(req)
+		if err != nil || res.StatusCode != http.StatusUnauthorized || !isReplayable(req) {
+			return res, err
+		}
+
+		authorization := req.Header.Get(`Authorization`)
+		if i := strings.IndexByte(authorization, ' '); i != -1 {
+			invalidator.Invalidate(authorization[i+1:])
+		}
+		token, err := source.Token(req.Context())
+		if err != nil {
+			// keep 401 response
+			return res, nil
+		}
+		retryReq, err := rewindRequest(req)
+		if err != nil {
+			return res, nil
+		}
+		CloseBody(res.Body)
+		retryReq.Header.Set(`Authorization`, token.Type()+` `+token.AccessToken)
+		return next.Do(retryReq)
+	})
+}
@@ -0,0 +1,112 @@
+package gorest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_client_BearerToken(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	tests := []struct {
+		name     string
+		operator TerminalOperator
+		want     string
+	}{
+		{
+			name:     "token",
+			operator: Get(server.URL).Client(httpClient).BearerToken("token"),
+			want:     "Bearer token",
+		},
+		{
+			name:     "token_source",
+			operator: Get(server.URL).Client(httpClient).BearerTokenSource(StaticTokenSource("static")),
+			want:     "Bearer static",
+		},
+		{
+			name:     "shared_client",
+			operator: New(server.URL, WithHTTPClient(httpClient), WithBearerToken("shared")).Get(),
+			want:     "Bearer shared",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.operator.HandleBody(func(body []uint8) error {
+				if diff := cmp.Diff(string(body), tt.want); diff != "" {
+					t.Errorf("invalid authorization, diff = %s", diff)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("failed to get %s", err)
+			}
+		})
+	}
+}
+
+// countingTokenSource returns new token every invalidation
+type countingTokenSource struct {
+	invalidated int32
+}
+
+func (s *countingTokenSource) Token(ctx context.Context) (*Token, error) {
+	return &Token{AccessToken: fmt.Sprintf("token-%d", atomic.LoadInt32(&s.invalidated)+1)}, nil
+}
+
+func (s *countingTokenSource) Invalidate(accessToken string) {
+	atomic.AddInt32(&s.invalidated, 1)
+}
+
+func Test_client_BearerTokenSource_retry_unauthorized(t *testing.T) {
+	var requests int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body := new(strings.Builder)
+		_, _ = io.Copy(body, r.Body)
+		_, _ = w.Write([]byte(body.String()))
+	}))
+	defer server.Close()
+
+	source := &countingTokenSource{}
+	err := Post(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		BearerTokenSource(source).
+		JSONString(`{"key":"value"}`).
+		HandleBody(func(body []uint8) error {
+			if diff := cmp.Diff(string(body), `{"key":"value"}`); diff != "" {
+				t.Errorf("body must be sent again, diff = %s", diff)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("failed to post %s", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("request must be sent twice, got => %d", got)
+	}
+	if got := atomic.LoadInt32(&source.invalidated); got != 1 {
+		t.Errorf("token must be invalidated once, got => %d", got)
+	}
+}
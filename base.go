@@ -0,0 +1,260 @@
+package gorest
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client holds default settings (base url, headers, auth, timeout ...)
+// shared by requests.
+// Client is safe for concurrent use, every request starts from a copy of the defaults.
+type Client struct {
+	defaults client
+}
+
+// Option configures default settings of Client
+type Option func(cli *client)
+
+// New requires base url and options for default settings.
+// BaseURL includes protocol like `http://` or `https://`
+// ex. https://api.github.com/repos/
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		defaults: client{
+			baseURL: baseURL,
+		},
+	}
+	for _, opt := range opts {
+		opt(&c.defaults)
+	}
+	return c
+}
+
+// WithHeader sets default header
+func WithHeader(key, value string) Option {
+	return func(cli *client) {
+		cli.Header(key, value)
+	}
+}
+
+// WithHeaders sets default headers
+func WithHeaders(headers http.Header) Option {
+	return func(cli *client) {
+		cli.Headers(headers)
+	}
+}
+
+// WithUserAgent sets default User-Agent header
+func WithUserAgent(userAgent string) Option {
+	return func(cli *client) {
+		cli.UserAgent(userAgent)
+	}
+}
+
+// WithCookie adds default cookie
+func WithCookie(cookie *http.Cookie) Option {
+	return func(cli *client) {
+		cli.Cookie(cookie)
+	}
+}
+
+// WithBasicAuth sets default basic auth
+func WithBasicAuth(username string, password string) Option {
+	return func(cli *client) {
+		cli.BasicAuth(username, password)
+	}
+}
+
+// WithDigestAuth sets default digest auth,
+// nonce from server is reused by all requests.
+func WithDigestAuth(username string, password string) Option {
+	return func(cli *client) {
+		cli.DigestAuth(username, password)
+	}
+}
+
+// WithBearerToken sets default bearer token
+func WithBearerToken(token string) Option {
+	return func(cli *client) {
+		cli.BearerToken(token)
+	}
+}
+
+// WithTokenSource sets default token source of bearer token,
+// token cached by the source is shared by all requests.
+func WithTokenSource(source TokenSource) Option {
+	return func(cli *client) {
+		cli.BearerTokenSource(source)
+	}
+}
+
+// WithSigner sets default signer of requests
+func WithSigner(signer Signer) Option {
+	return func(cli *client) {
+		cli.Sign(signer)
+	}
+}
+
+// WithHTTPClient sets default http client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(cli *client) {
+		cli.Client(httpClient)
+	}
+}
+
+// WithTimeout sets default time limit of whole api call, see TerminalOperator.Timeout.
+// http client passed by WithHTTPClient is never modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cli *client) {
+		cli.Timeout(timeout)
+	}
+}
+
+// WithTimeouts sets default time limits of phases, see TerminalOperator.Timeouts
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(cli *client) {
+		cli.Timeouts(timeouts)
+	}
+}
+
+// WithErrorModel sets default error model, see TerminalOperator.OnError
+func WithErrorModel(model interface{}) Option {
+	return func(cli *client) {
+		cli.OnError(model)
+	}
+}
+
+// WithMaxErrorBody sets default max size of response body captured by InvalidStatusCodeError
+func WithMaxErrorBody(size int64) Option {
+	return func(cli *client) {
+		cli.MaxErrorBody(size)
+	}
+}
+
+// WithErrorBodyRedactor sets default function which removes sensitive data
+// from response body captured by InvalidStatusCodeError
+func WithErrorBodyRedactor(f func(body []byte) []byte) Option {
+	return func(cli *client) {
+		cli.RedactErrorBody(f)
+	}
+}
+
+// WithRetry sets default retry policy
+func WithRetry(policy RetryPolicy) Option {
+	return func(cli *client) {
+		cli.Retry(policy)
+	}
+}
+
+// WithLogger sets default logger, see TerminalOperator.Log
+func WithLogger(logger Logger, options LogOptions) Option {
+	return func(cli *client) {
+		cli.Log(logger, options)
+	}
+}
+
+// WithMiddleware adds default middlewares,
+// they run before middlewares added by TerminalOperator.Use
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(cli *client) {
+		cli.Use(middlewares...)
+	}
+}
+
+// Get returns GET request which has default settings
+func (c *Client) Get() TerminalOperator {
+	return c.newRequest(get)
+}
+
+// Post returns POST request which has default settings
+func (c *Client) Post() TerminalOperator {
+	return c.newRequest(post)
+}
+
+// Put returns PUT request which has default settings
+func (c *Client) Put() TerminalOperator {
+	return c.newRequest(put)
+}
+
+// Delete returns DELETE request which has default settings
+func (c *Client) Delete() TerminalOperator {
+	return c.newRequest(del)
+}
+
+// Patch returns PATCH request which has default settings
+func (c *Client) Patch() TerminalOperator {
+	return c.newRequest(patch)
+}
+
+// Head returns HEAD request which has default settings
+func (c *Client) Head() TerminalOperator {
+	return c.newRequest(head)
+}
+
+// Options returns OPTIONS request which has default settings
+func (c *Client) Options() TerminalOperator {
+	return c.newRequest(options)
+}
+
+// Method returns request of custom verb which has default settings
+func (c *Client) Method(verb string) TerminalOperator {
+	return c.newRequest(requestMethod(verb))
+}
+
+func (c *Client) newRequest(method requestMethod) *client {
+	cli := c.defaults.clone()
+	cli.method = method
+	return cli
+}
+
+func (cli *client) Clone() TerminalOperator {
+	return cli.clone()
+}
+
+// clone returns copy of cli, which never shares slices and maps with cli
+func (cli *client) clone() *client {
+	copied := *cli
+	if cli.paths != nil {
+		copied.paths = append([]string{}, cli.paths...)
+	}
+	if cli.pathParams != nil {
+		copied.pathParams = make(map[string]string, len(cli.pathParams))
+		for key, value := range cli.pathParams {
+			copied.pathParams[key] = value
+		}
+	}
+	if cli.query != nil {
+		copied.query = copyValues(cli.query)
+	}
+	if cli.queryStructs != nil {
+		copied.queryStructs = append([]interface{}{}, cli.queryStructs...)
+	}
+	if cli.headers != nil {
+		copied.headers = cli.headers.Clone()
+	}
+	if values, ok := cli.params.(url.Values); ok {
+		copied.params = copyValues(values)
+	}
+	if cli.middlewares != nil {
+		copied.middlewares = append([]Middleware{}, cli.middlewares...)
+	}
+	if cli.cookies != nil {
+		copied.cookies = append([]*http.Cookie{}, cli.cookies...)
+	}
+	if cli.expectedStatuses != nil {
+		copied.expectedStatuses = append([]int{}, cli.expectedStatuses...)
+	}
+	if cli.multipartSettings != nil {
+		copied.multipartSettings = append([]multipartSetting{}, cli.multipartSettings...)
+	}
+	return &copied
+}
+
+func copyValues(values url.Values) url.Values {
+	copied := make(url.Values, len(values))
+	for key, value := range values {
+		copied[key] = append([]string{}, value...)
+	}
+	return copied
+}
@@ -0,0 +1,322 @@
+package gorest
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNew(t *testing.T) {
+	httpClient := &http.Client{}
+	model := &testAPIError{}
+
+	got := New("https://sample.com",
+		WithHeader("X-Api-Key", "token"),
+		WithBasicAuth("user", "pass"),
+		WithHTTPClient(httpClient),
+		WithTimeout(time.Second),
+		WithErrorModel(model),
+	)
+	want := &Client{
+		defaults: client{
+			baseURL:    "https://sample.com",
+			headers:    http.Header{"X-Api-Key": {"token"}},
+			username:   strPtr("user"),
+			password:   strPtr("pass"),
+			client:     httpClient,
+			timeout:    time.Second,
+			errorModel: model,
+		},
+	}
+	if diff := cmp.Diff(got, want, cmp.AllowUnexported(Client{}, client{})); diff != "" {
+		t.Errorf("New() diff = %s", diff)
+	}
+}
+
+func TestClient_methods(t *testing.T) {
+	base := New("https://sample.com", WithHeader("X-Api-Key", "token"))
+
+	tests := []struct {
+		name string
+		got  TerminalOperator
+		want requestMethod
+	}{
+		{name: "get", got: base.Get(), want: "GET"},
+		{name: "post", got: base.Post(), want: "POST"},
+		{name: "put", got: base.Put(), want: "PUT"},
+		{name: "delete", got: base.Delete(), want: "DELETE"},
+		{name: "patch", got: base.Patch(), want: "PATCH"},
+		{name: "head", got: base.Head(), want: "HEAD"},
+		{name: "options", got: base.Options(), want: "OPTIONS"},
+		{name: "custom", got: base.Method("PURGE"), want: "PURGE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := &client{
+				method:  tt.want,
+				baseURL: "https://sample.com",
+				headers: http.Header{"X-Api-Key": {"token"}},
+			}
+			if diff := cmp.Diff(tt.got, want, cmp.AllowUnexported(client{})); diff != "" {
+				t.Errorf("diff = %s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_defaults_not_shared(t *testing.T) {
+	base := New("https://sample.com", WithHeader("X-Api-Key", "token"))
+
+	base.Get().
+		Path("/users").
+		URLParam("key", "value").
+		Header("X-Request-Id", "1").
+		URLEncoded("key", "value")
+
+	want := client{
+		baseURL: "https://sample.com",
+		headers: http.Header{"X-Api-Key": {"token"}},
+	}
+	if diff := cmp.Diff(base.defaults, want, cmp.AllowUnexported(client{})); diff != "" {
+		t.Errorf("defaults are modified, diff = %s", diff)
+	}
+}
+
+func TestClient_concurrent(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "token" {
+			t.Errorf("invalid api key, got => %s", r.Header.Get("X-Api-Key"))
+		}
+		_, _ = w.Write([]byte(r.Header.Get("X-Request-Id")))
+	}))
+	defer server.Close()
+
+	base := New(server.URL,
+		WithHeader("X-Api-Key", "token"),
+		WithHTTPClient(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			requestID := fmt.Sprintf("%d", i)
+			err := base.Get().
+				Header("X-Request-Id", requestID).
+				HandleBody(func(body []uint8) error {
+					if string(body) != requestID {
+						t.Errorf("invalid request id, got => %s, want => %s", body, requestID)
+					}
+					return nil
+				})
+			if err != nil {
+				t.Errorf("failed to get %s", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_client_execute_multipart_concurrently(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("failed to read file %s", err)
+			return
+		}
+		defer file.Close()
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			t.Errorf("failed to read file %s", err)
+		}
+		_, _ = w.Write([]byte(r.FormValue("id") + " " + string(content)))
+	}))
+	defer server.Close()
+
+	file, err := os.Open("testdata/sample.golden")
+	if err != nil {
+		t.Fatalf("cannot open file %q: %v", "testdata/sample.golden", err)
+	}
+	defer file.Close()
+
+	base := Post(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}})
+	base.MultipartData("file", file, false)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := base.Clone().MultipartData("id", strings.NewReader(fmt.Sprint(i)), false)
+			want := fmt.Sprintf("%d header1,header2\nvalue1,value2\n", i)
+			// readers of parts are shared by clones, and read repeatedly
+			for j := 0; j < 2; j++ {
+				err := request.HandleBody(func(body []uint8) error {
+					if string(body) != want {
+						t.Errorf("invalid response, got => %q, want => %q", body, want)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Errorf("failed to post %s", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_client_Clone(t *testing.T) {
+	base := Post("https://sample.com").
+		Path("/users").
+		URLParam("key", "value").
+		Header("X-Api-Key", "token").
+		Cookie(&http.Cookie{Name: "session", Value: "1"})
+	want := &client{
+		method:  "POST",
+		baseURL: "https://sample.com",
+		paths:   []string{"/users"},
+		query:   url.Values{"key": {"value"}},
+		headers: http.Header{"X-Api-Key": {"token"}},
+		cookies: []*http.Cookie{{Name: "session", Value: "1"}},
+	}
+
+	forked := base.Clone()
+	forked.Path("/1").
+		URLParam("key", "other").
+		Header("X-Api-Key", "other").
+		AddHeader("Accept", "application/json").
+		Cookie(&http.Cookie{Name: "lang", Value: "ja"}).
+		URLEncoded("name", "value")
+	base.Clone().URLEncoded("name", "other")
+
+	if diff := cmp.Diff(base, want, cmp.AllowUnexported(client{})); diff != "" {
+		t.Errorf("original is modified by clone, diff = %s", diff)
+	}
+}
+
+func Test_client_build_idempotent(t *testing.T) {
+	file, err := os.Open("testdata/sample.golden")
+	if err != nil {
+		t.Fatalf("cannot open file %q: %v", "testdata/sample.golden", err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		name    string
+		request Executor
+	}{
+		{
+			name:    "json_struct",
+			request: Post("https://sample.com").JSONStruct(struct{ Name string }{Name: "name"}),
+		},
+		{
+			name:    "url_encoded",
+			request: Post("https://sample.com").URLEncoded("key", "value"),
+		},
+		{
+			name: "query_struct",
+			request: Get("https://sample.com").QueryStruct(struct {
+				Name string `url:"name"`
+			}{Name: "name"}),
+		},
+		{
+			name: "multipart",
+			request: Post("https://sample.com").
+				MultipartData("name", strings.NewReader("value"), false).
+				MultipartAsFormFile("buffer", "a.txt", bytes.NewBufferString("buffer"), false).
+				MultipartAsFormFile("reader", "b.txt", io.MultiReader(strings.NewReader("reader")), false).
+				MultipartData("file", file, false),
+		},
+		{
+			name: "streaming_multipart",
+			request: Post("https://sample.com").
+				MultipartData("name", strings.NewReader("value"), false).
+				MultipartData("file", file, false).
+				StreamMultipart(true),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dumps []string
+			for i := 0; i < 2; i++ {
+				req, err := tt.request.Build()
+				if err != nil {
+					t.Fatalf("Build() error = %v", err)
+				}
+				dump, err := httputil.DumpRequestOut(req, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// boundary of multipart is random
+				if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+					dump = bytes.ReplaceAll(dump, []byte(params["boundary"]), []byte("boundary"))
+				}
+				dumps = append(dumps, string(dump))
+			}
+			if diff := cmp.Diff(dumps[0], dumps[1]); diff != "" {
+				t.Errorf("request must be same, diff = %s", diff)
+			}
+		})
+	}
+}
+
+func Test_client_execute_concurrently(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body %s", err)
+		}
+		_, _ = w.Write([]byte(r.URL.Path + " " + string(body)))
+	}))
+	defer server.Close()
+
+	base := Post(server.URL).
+		Path("/users").
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := base.Clone().Path("/%d", i).JSONStruct(struct{ ID int }{ID: i})
+			want := fmt.Sprintf(`/users/%d {"ID":%d}`, i, i)
+			// same request is executed repeatedly
+			for j := 0; j < 2; j++ {
+				err := request.HandleBody(func(body []uint8) error {
+					if string(body) != want {
+						t.Errorf("invalid response, got => %s, want => %s", body, want)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Errorf("failed to post %s", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -1,8 +1,12 @@
 package gorest
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"net/textproto"
+	"net/url"
+	"time"
 )
 
 // Get requires base url for reuse this instance.
@@ -25,6 +29,9 @@ func Post(baseURL string) TerminalOperator {
 	}
 }
 
+// Put requires base url for reuse this instance.
+// BaseURL includes protocol like `http://` or `https://`
+// ex. https://api.github.com/repos/
 func Put(baseURL string) TerminalOperator {
 	return &client{
 		baseURL: baseURL,
@@ -32,41 +39,231 @@ func Put(baseURL string) TerminalOperator {
 	}
 }
 
+// Delete requires base url for reuse this instance.
+// BaseURL includes protocol like `http://` or `https://`
+// ex. https://api.github.com/repos/
+func Delete(baseURL string) TerminalOperator {
+	return &client{
+		baseURL: baseURL,
+		method:  del,
+	}
+}
+
+// Patch requires base url for reuse this instance.
+// BaseURL includes protocol like `http://` or `https://`
+// ex. https://api.github.com/repos/
+func Patch(baseURL string) TerminalOperator {
+	return &client{
+		baseURL: baseURL,
+		method:  patch,
+	}
+}
+
+// Head requires base url for reuse this instance.
+// Response body of HEAD is never read, HandleBody receives empty body.
+// ex. https://api.github.com/repos/
+func Head(baseURL string) TerminalOperator {
+	return &client{
+		baseURL: baseURL,
+		method:  head,
+	}
+}
+
+// Options requires base url for reuse this instance.
+// BaseURL includes protocol like `http://` or `https://`
+// ex. https://api.github.com/repos/
+func Options(baseURL string) TerminalOperator {
+	return &client{
+		baseURL: baseURL,
+		method:  options,
+	}
+}
+
+// Method requires http method name and base url,
+// for custom verbs like `PURGE`.
+// verb is sent as it is, so it is case sensitive.
+func Method(verb string, baseURL string) TerminalOperator {
+	return &client{
+		baseURL: baseURL,
+		method:  requestMethod(verb),
+	}
+}
+
 type client struct {
-	method               requestMethod
-	contentType          contentType
-	baseURL              string
-	paths                []string
-	urlParams            []string
-	username             *string
-	password             *string
-	headers              map[string]string
-	params               interface{}
-	hasJsonStruct        bool
-	hasRawFormUrlEncoded bool
-	multipartSettings    []multipartSetting
-	responseHandler      func(*http.Request, *http.Response) (*http.Response, error)
-	client               *http.Client
+	method                 requestMethod
+	contentType            contentType
+	baseURL                string
+	paths                  []string
+	pathParams             map[string]string
+	query                  url.Values
+	queryStructs           []interface{}
+	username               *string
+	password               *string
+	headers                http.Header
+	params                 interface{}
+	hasJsonStruct          bool
+	hasRawFormUrlEncoded   bool
+	multipartSettings      []multipartSetting
+	multipartStreaming     bool
+	multipartContentLength bool
+	responseHandler        func(*http.Request, *http.Response) (*http.Response, error)
+	client                 *http.Client
+	ctx                    context.Context
+	errorModel             interface{}
+	retryPolicy            *RetryPolicy
+	timeout                time.Duration
+	middlewares            []Middleware
+	uploadProgress         ProgressFunc
+	downloadProgress       ProgressFunc
+	expectedStatuses       []int
+	acceptStatus           func(code int) bool
+	noRedirect             bool
+	maxErrorBodySize       int64
+	errorBodyRedactor      func(body []byte) []byte
+	bearerToken            *string
+	tokenSource            TokenSource
+	signer                 Signer
+	digestAuth             *digestAuth
+	cookies                []*http.Cookie
+	compression            Encoding
+	timeouts               Timeouts
+	logger                 *requestLogger
 }
 
 // TerminalOperator executes web api and process result
 type TerminalOperator interface {
 	// endpoint
 
+	// Path adds path, slash between paths is normalized.
+	// pathFmt can have placeholders like `/repos/{owner}/{repo}`, they are replaced by PathParam.
+	// braces in args are not placeholders.
 	Path(pathFmt string, args ...interface{}) TerminalOperator
+	// PathParam sets value of placeholder in path, value is escaped.
+	// if placeholder has no value, error occurs when executing.
+	PathParam(key string, value string) TerminalOperator
+	// URLParam adds query parameter, key can be repeated
 	URLParam(key string, value string) TerminalOperator
+	// URLParams adds query parameters
+	URLParams(params map[string][]string) TerminalOperator
+	// QueryStruct adds query parameters from struct fields which have `url` tag
+	// like `url:"name,omitempty"`.
+	// if receive invalid, error occurs when executing.
+	QueryStruct(v interface{}) TerminalOperator
 
 	// basic auth
 
 	BasicAuth(username string, password string) TerminalOperator
 
+	// digest auth
+
+	// DigestAuth answers 401 challenge of digest authentication (RFC 7616),
+	// MD5, SHA-256 and their -sess variants with qop=auth are supported.
+	// request is sent once again with Authorization after the challenge.
+	DigestAuth(username string, password string) TerminalOperator
+
+	// bearer token
+
+	// BearerToken sets `Authorization: Bearer token` header
+	BearerToken(token string) TerminalOperator
+	// BearerTokenSource sets token from source to Authorization header,
+	// like ClientCredentialsTokenSource.
+	// if source implements TokenInvalidator and server responds 401,
+	// request is sent once again with new token.
+	BearerTokenSource(source TokenSource) TerminalOperator
+
+	// signing
+
+	// Sign signs request by signer after request is fully built,
+	// like HMACSigner or AWSV4Signer.
+	Sign(signer Signer) TerminalOperator
+
 	// header
 
+	// Header sets header, values of same key are replaced
 	Header(key, value string) TerminalOperator
+	// AddHeader adds header, key can be repeated like `Accept`
+	AddHeader(key, value string) TerminalOperator
+	// Headers sets headers, values of same keys are replaced
+	Headers(headers http.Header) TerminalOperator
+	// Accept sets `Accept` header, each media type is sent as separate header
+	Accept(mediaTypes ...string) TerminalOperator
+	// UserAgent sets `User-Agent` header
+	UserAgent(userAgent string) TerminalOperator
+	// ContentType overrides `Content-Type` header decided by body,
+	// like `application/merge-patch+json`.
+	ContentType(contentType string) TerminalOperator
+	// Cookie adds cookie to request, it is sent with cookies of jar of http client
+	Cookie(cookie *http.Cookie) TerminalOperator
 
 	// client
 	Client(client *http.Client) TerminalOperator
 
+	// Context sets ctx for cancellation and deadline,
+	// Execute and HandleBody use it.
+	Context(ctx context.Context) TerminalOperator
+
+	// Timeout limits whole api call including retries and reading response body,
+	// http client is never modified. TimeoutError is returned when it is expired.
+	Timeout(timeout time.Duration) TerminalOperator
+	// Timeouts limits phases of each attempt like connect and first byte,
+	// TimeoutError has the expired phase.
+	Timeouts(timeouts Timeouts) TerminalOperator
+
+	// Retry retries api call by policy.
+	// request body is sent again, so JSON, URLEncoded and Multipart are supported.
+	Retry(policy RetryPolicy) TerminalOperator
+
+	// Log writes method, url, status and duration of api call to logger,
+	// headers and bodies are also written by level of options.
+	// Authorization, Cookie and X-Api-Key headers are always redacted.
+	Log(logger Logger, options LogOptions) TerminalOperator
+
+	// Use adds middlewares which wrap sending request.
+	// middlewares run in order of registration,
+	// the first one receives request first and response last.
+	Use(middlewares ...Middleware) TerminalOperator
+
+	// CompressBody compresses JSON or URLEncoded body by Gzip or Deflate,
+	// and sets Content-Encoding header. Multipart body is error.
+	CompressBody(encoding Encoding) TerminalOperator
+
+	// OnUploadProgress receives sent bytes of request body while sending,
+	// total is -1 if length of body is unknown.
+	OnUploadProgress(f func(sent, total int64)) TerminalOperator
+	// OnDownloadProgress receives received bytes of response body
+	// while HandleStream, DownloadTo or DownloadFile reads it,
+	// total is -1 if length of body is unknown.
+	OnDownloadProgress(f func(received, total int64)) TerminalOperator
+
+	// status
+
+	// ExpectStatus sets valid status codes, other status codes are error.
+	// Execute also validates status code if it is set.
+	ExpectStatus(codes ...int) TerminalOperator
+	// AcceptStatus sets function which decides valid status code.
+	// it is combined with ExpectStatus, status code accepted by either is valid.
+	// Execute also validates status code if it is set.
+	AcceptStatus(f func(code int) bool) TerminalOperator
+	// NoRedirect never follows redirect, and 3xx status code is error
+	// unless ExpectStatus or AcceptStatus accepts it.
+	// Execute also validates status code if it is set.
+	NoRedirect() TerminalOperator
+
+	// error
+
+	// OnError registers error model like `&APIError{}`.
+	// if status code is over 400, response body is decoded into a new model,
+	// and it is set to InvalidStatusCodeError.Model.
+	// if the model implements error, errors.As finds it.
+	OnError(model interface{}) TerminalOperator
+	// MaxErrorBody limits size of response body captured by InvalidStatusCodeError,
+	// default is 64KiB.
+	MaxErrorBody(size int64) TerminalOperator
+	// RedactErrorBody sets function which removes sensitive data from
+	// response body captured by InvalidStatusCodeError, like RedactJSONFields.
+	// body truncated by MaxErrorBody is also passed to f.
+	RedactErrorBody(f func(body []byte) []byte) TerminalOperator
+
 	// body
 
 	JSON(json []byte) JSONContent
@@ -85,11 +282,22 @@ type TerminalOperator interface {
 
 	MultipartData(key string, value io.Reader, forceMultipart bool) Multipart
 	MultipartAsFormFile(key string, fileName string, reader io.Reader, forceMultipart bool) Multipart
+	// MultipartWithContentType adds part which has the content type like `image/png`.
+	// fileName can be empty.
+	MultipartWithContentType(key string, fileName string, contentType string, reader io.Reader) Multipart
+	// MultipartPart adds part which has the header, Content-Disposition is never added.
+	MultipartPart(header textproto.MIMEHeader, reader io.Reader) Multipart
 
 	// HandleResponse require response handler,
 	// if create a new response, MUST close old res.Body
 	HandleResponse(func(*http.Request, *http.Response) (*http.Response, error)) ResponseHandler
 
+	// Clone returns copy of the request for branching it like different paths,
+	// settings of the copy never affect the original.
+	// token source and digest auth are shared.
+	// readers of multipart body are also shared, but they are never consumed by building request.
+	Clone() TerminalOperator
+
 	// execute
 
 	Executor
@@ -116,17 +324,59 @@ type URLEncoded interface {
 	Executor
 }
 
-// Multipart provides methods for set multipart data(including file)
+// Multipart provides methods for set multipart data(including file).
+// readers are never closed, and they are read from the same offset every time request is built.
+// reader which is not io.ReaderAt and io.Seeker like *os.File is buffered at first read,
+// or read only once by StreamMultipart.
 type Multipart interface {
 	MultipartData(key string, value io.Reader, forceMultipart bool) Multipart
 	MultipartAsFormFile(key string, fileName string, reader io.Reader, forceMultipart bool) Multipart
+	// MultipartWithContentType adds part which has the content type like `image/png`.
+	// fileName can be empty.
+	MultipartWithContentType(key string, fileName string, contentType string, reader io.Reader) Multipart
+	// MultipartPart adds part which has the header, Content-Disposition is never added.
+	MultipartPart(header textproto.MIMEHeader, reader io.Reader) Multipart
+	// StreamMultipart writes parts while sending request, instead of buffering in memory.
+	// if computeContentLength is true and sizes of all readers are known,
+	// Content-Length is set, otherwise body is sent by chunked encoding.
+	// streamed body is never sent again by Retry.
+	StreamMultipart(computeContentLength bool) Multipart
 	Executor
 }
 
 // Executor provides methods for executing api
 type Executor interface {
+	// Build builds request without sending it, the request has encoded body,
+	// headers and Authorization except digest auth which needs challenge from server.
+	// it can be sent by other http client.
+	// readers of multipart body are not consumed by Build, so the builder can be executed after Build,
+	// except that StreamMultipart reads reader which is not io.ReaderAt and io.Seeker only once,
+	// by either the built request or the builder.
+	Build() (*http.Request, error)
+	// BuildContext is same as Build, but request has ctx
+	BuildContext(ctx context.Context) (*http.Request, error)
+	// AsCurl builds request without sending it, and returns curl command line.
+	// credentials are not redacted.
+	// body of StreamMultipart is not read, and it is written as `--data-binary @-`.
+	AsCurl() (string, error)
 	Execute() (resp *http.Response, err error)
+	ExecuteContext(ctx context.Context) (resp *http.Response, err error)
 	HandleBody(f func(body []uint8) error) error
+	HandleBodyContext(ctx context.Context, f func(body []uint8) error) error
+	// DecodeJSON validates status code like HandleBody,
+	// and decodes json response body into out.
+	DecodeJSON(out interface{}) error
+	// DecodeJSONStrict is same as DecodeJSON,
+	// but unknown fields in response body are error.
+	DecodeJSONStrict(out interface{}) error
+	// HandleStream validates status code like HandleBody,
+	// and passes response body reader without reading all of it.
+	HandleStream(f func(r io.Reader) error) error
+	// DownloadTo validates status code like HandleBody, and copies response body into w
+	DownloadTo(w io.Writer) error
+	// DownloadFile validates status code like HandleBody, and saves response body as file.
+	// file is written atomically, path never has incomplete content.
+	DownloadFile(path string) error
 	// HandleResponse require response handler,
 	// if create a new response, MUST close old res.Body
 	HandleResponse(func(*http.Request, *http.Response) (*http.Response, error)) ResponseHandler
@@ -135,4 +385,10 @@ type Executor interface {
 // ResponseHandler provides wrapper methods with handling error(ex. http status code)
 type ResponseHandler interface {
 	HandleBody(f func(body []uint8) error) error
+	HandleBodyContext(ctx context.Context, f func(body []uint8) error) error
+	DecodeJSON(out interface{}) error
+	DecodeJSONStrict(out interface{}) error
+	HandleStream(f func(r io.Reader) error) error
+	DownloadTo(w io.Writer) error
+	DownloadFile(path string) error
 }
@@ -94,3 +94,167 @@ func TestPut(t *testing.T) {
 		})
 	}
 }
+
+func TestDelete(t *testing.T) {
+	type args struct {
+		baseURL string
+	}
+	tests := []struct {
+		name string
+		args args
+		want TerminalOperator
+	}{
+		{
+			name: "method_is_delete",
+			args: args{
+				baseURL: "https://sample.com",
+			},
+			want: &client{
+				method:      "DELETE",
+				contentType: "",
+				baseURL:     "https://sample.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Delete(tt.args.baseURL); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Delete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	type args struct {
+		baseURL string
+	}
+	tests := []struct {
+		name string
+		args args
+		want TerminalOperator
+	}{
+		{
+			name: "method_is_patch",
+			args: args{
+				baseURL: "https://sample.com",
+			},
+			want: &client{
+				method:      "PATCH",
+				contentType: "",
+				baseURL:     "https://sample.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Patch(tt.args.baseURL); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Patch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	type args struct {
+		baseURL string
+	}
+	tests := []struct {
+		name string
+		args args
+		want TerminalOperator
+	}{
+		{
+			name: "method_is_head",
+			args: args{
+				baseURL: "https://sample.com",
+			},
+			want: &client{
+				method:      "HEAD",
+				contentType: "",
+				baseURL:     "https://sample.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Head(tt.args.baseURL); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Head() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	type args struct {
+		baseURL string
+	}
+	tests := []struct {
+		name string
+		args args
+		want TerminalOperator
+	}{
+		{
+			name: "method_is_options",
+			args: args{
+				baseURL: "https://sample.com",
+			},
+			want: &client{
+				method:      "OPTIONS",
+				contentType: "",
+				baseURL:     "https://sample.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Options(tt.args.baseURL); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Options() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMethod(t *testing.T) {
+	type args struct {
+		verb    string
+		baseURL string
+	}
+	tests := []struct {
+		name string
+		args args
+		want TerminalOperator
+	}{
+		{
+			name: "custom_verb",
+			args: args{
+				verb:    "PURGE",
+				baseURL: "https://sample.com",
+			},
+			want: &client{
+				method:      "PURGE",
+				contentType: "",
+				baseURL:     "https://sample.com",
+			},
+		},
+		{
+			name: "standard_verb",
+			args: args{
+				verb:    "DELETE",
+				baseURL: "https://sample.com",
+			},
+			want: &client{
+				method:      "DELETE",
+				contentType: "",
+				baseURL:     "https://sample.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Method(tt.args.verb, tt.args.baseURL); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Method() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package gorest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Encoding is content coding of request body like gzip
+type Encoding string
+
+const (
+	// Gzip compresses by gzip
+	Gzip Encoding = "gzip"
+	// Deflate compresses by zlib format, as `deflate` of HTTP
+	Deflate Encoding = "deflate"
+)
+
+// DecoderFunc returns reader which decodes r
+type DecoderFunc func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]DecoderFunc{
+		string(Gzip): func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		string(Deflate): newDeflateReader,
+	}
+	// decoderNames keeps order of Accept-Encoding
+	decoderNames = []string{string(Gzip), string(Deflate)}
+)
+
+// RegisterDecoder registers decoder of response body which has the Content-Encoding,
+// like `br` or `zstd`. encoding is also added to Accept-Encoding header.
+// registered decoder replaces decoder of same encoding.
+//
+//	gorest.RegisterDecoder(`br`, func(r io.Reader) (io.ReadCloser, error) {
+//		return ioutil.NopCloser(brotli.NewReader(r)), nil
+//	})
+func RegisterDecoder(encoding string, decoder DecoderFunc) {
+	encoding = strings.ToLower(encoding)
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if _, ok := decoders[encoding]; !ok {
+		decoderNames = append(decoderNames, encoding)
+	}
+	decoders[encoding] = decoder
+}
+
+// lookupDecoder returns registered decoder of encoding
+func lookupDecoder(encoding string) (DecoderFunc, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	decoder, ok := decoders[strings.ToLower(encoding)]
+	return decoder, ok
+}
+
+// acceptEncoding returns value of Accept-Encoding header from registered decoders
+func acceptEncoding() string {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	return strings.Join(decoderNames, `, `)
+}
+
+// newDeflateReader decodes zlib format, and raw deflate sent by some servers
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil && len(header) == 0 {
+		return nil, err
+	}
+	// zlib header has deflate method and check bits
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// compressBody compresses request body by encoding
+func compressBody(body io.Reader, encoding Encoding) (*bytes.Buffer, error) {
+	var compressed bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case Gzip:
+		writer = gzip.NewWriter(&compressed)
+	case Deflate:
+		writer = zlib.NewWriter(&compressed)
+	default:
+		return nil, fmt.Errorf(`unsupported content encoding %s`, encoding)
+	}
+	if _, err := io.Copy(writer, body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return &compressed, nil
+}
+
+// compress compresses request body by encoding set by CompressBody
+func (cli *client) compress(body io.Reader) (io.Reader, error) {
+	if cli.compression == `` || body == nil {
+		return body, nil
+	}
+	if len(cli.multipartSettings) != 0 {
+		return nil, errors.New(`compression is not supported for multipart body`)
+	}
+	return compressBody(body, cli.compression)
+}
+
+// decodesResponse reports whether gorest sets Accept-Encoding and decodes response body,
+// it is false if Accept-Encoding is set by Header.
+func (cli *client) decodesResponse() bool {
+	return cli.headers.Get(`Accept-Encoding`) == ``
+}
+
+// decodeResponse sends Accept-Encoding of registered decoders,
+// and decodes response body which has registered Content-Encoding.
+// the header is added only to outgoing request, so built request is left to transport.
+func decodeResponse(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get(`Accept-Encoding`) == `` {
+			req = req.Clone(req.Context())
+			req.Header.Set(`Accept-Encoding`, acceptEncoding())
+		}
+		res, err := next.Do(req)
+		if err != nil || res.Body == nil || res.Body == http.NoBody {
+			return res, err
+		}
+		encoding := strings.TrimSpace(res.Header.Get(`Content-Encoding`))
+		if encoding == `` || strings.EqualFold(encoding, `identity`) {
+			return res, nil
+		}
+		decoder, ok := lookupDecoder(encoding)
+		if !ok {
+			return res, nil
+		}
+
+		res.Body = &decodingBody{body: res.Body, decoder: decoder}
+		res.Header.Del(`Content-Encoding`)
+		res.Header.Del(`Content-Length`)
+		res.ContentLength = -1
+		res.Uncompressed = true
+		return res, nil
+	})
+}
+
+// decodingBody creates decoder at first Read, so empty body like HEAD is never read
+type decodingBody struct {
+	body    io.ReadCloser
+	decoder DecoderFunc
+	decoded io.ReadCloser
+	err     error
+}
+
+func (b *decodingBody) Read(p []byte) (int, error) {
+	if b.decoded == nil && b.err == nil {
+		b.decoded, b.err = b.decoder(b.body)
+		if b.err == io.EOF {
+			// empty body is not encoded
+			b.decoded, b.err = nil, io.EOF
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.decoded.Read(p)
+}
+
+func (b *decodingBody) Close() error {
+	if b.decoded != nil {
+		_ = b.decoded.Close()
+	}
+	return b.body.Close()
+}
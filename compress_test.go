@@ -0,0 +1,207 @@
+package gorest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_client_CompressBody(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		var err error
+		switch r.Header.Get("Content-Encoding") {
+		case "gzip":
+			body, err = gzip.NewReader(r.Body)
+		case "deflate":
+			body, err = zlib.NewReader(r.Body)
+		}
+		if err != nil {
+			t.Errorf("failed to decode body %s", err)
+			return
+		}
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			t.Errorf("failed to read body %s", err)
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Content-Encoding") + ":" + string(data)))
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	tests := []struct {
+		name    string
+		request Executor
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "gzip_json",
+			request: Post(server.URL).Client(httpClient).CompressBody(Gzip).JSONString(`{"name":"gorest"}`),
+			want:    `gzip:{"name":"gorest"}`,
+		},
+		{
+			name:    "deflate_url_encoded",
+			request: Post(server.URL).Client(httpClient).CompressBody(Deflate).URLEncoded("key", "value"),
+			want:    `deflate:key=value`,
+		},
+		{
+			name:    "no_body",
+			request: Get(server.URL).Client(httpClient).CompressBody(Gzip),
+			want:    `:`,
+		},
+		{
+			name: "multipart",
+			request: Post(server.URL).Client(httpClient).CompressBody(Gzip).
+				MultipartData("key", strings.NewReader("value"), false),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported",
+			request: Post(server.URL).Client(httpClient).CompressBody("br").JSONString(`{}`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			err := tt.request.HandleBody(func(body []uint8) error {
+				got = string(body)
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HandleBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("invalid body, diff = %s", diff)
+			}
+		})
+	}
+}
+
+func Test_client_decode_response(t *testing.T) {
+	// x-base64 is registered only in this test
+	RegisterDecoder("X-Base64", func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+	})
+	defer func() {
+		decodersMu.Lock()
+		delete(decoders, "x-base64")
+		decoderNames = decoderNames[:len(decoderNames)-1]
+		decodersMu.Unlock()
+	}()
+
+	const content = `{"message":"compressed response"}`
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.URL.Query().Get("encoding")
+		var body bytes.Buffer
+		var writer io.WriteCloser
+		switch encoding {
+		case "gzip":
+			writer = gzip.NewWriter(&body)
+		case "deflate":
+			writer = zlib.NewWriter(&body)
+		case "raw_deflate":
+			writer, _ = flate.NewWriter(&body, flate.DefaultCompression)
+			encoding = "deflate"
+		case "x-base64":
+			writer = base64.NewEncoder(base64.StdEncoding, &body)
+		}
+		_, _ = writer.Write([]byte(content))
+		_ = writer.Close()
+
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = w.Write(body.Bytes())
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	for _, encoding := range []string{"gzip", "deflate", "raw_deflate", "x-base64"} {
+		t.Run(encoding, func(t *testing.T) {
+			var got map[string]string
+			err := Get(server.URL).Client(httpClient).URLParam("encoding", encoding).DecodeJSON(&got)
+			if err != nil {
+				t.Fatalf("failed to decode %s", err)
+			}
+			if diff := cmp.Diff(got, map[string]string{"message": "compressed response"}); diff != "" {
+				t.Errorf("invalid response, diff = %s", diff)
+			}
+
+			var streamed bytes.Buffer
+			if err := Get(server.URL).Client(httpClient).URLParam("encoding", encoding).DownloadTo(&streamed); err != nil {
+				t.Fatalf("failed to download %s", err)
+			}
+			if diff := cmp.Diff(streamed.String(), content); diff != "" {
+				t.Errorf("invalid streamed response, diff = %s", diff)
+			}
+		})
+	}
+
+	t.Run("accept_encoding", func(t *testing.T) {
+		res, err := Get(server.URL).Client(httpClient).URLParam("encoding", "gzip").Execute()
+		if err != nil {
+			t.Fatalf("failed to execute %s", err)
+		}
+		defer CloseBody(res.Body)
+		if diff := cmp.Diff(res.Header.Get("X-Accept-Encoding"), "gzip, deflate, x-base64"); diff != "" {
+			t.Errorf("invalid Accept-Encoding, diff = %s", diff)
+		}
+		if res.Header.Get("Content-Encoding") != "" || !res.Uncompressed {
+			t.Error("decoded response must not have Content-Encoding")
+		}
+	})
+
+	t.Run("built_request_left_to_transport", func(t *testing.T) {
+		req, err := Get(server.URL).URLParam("encoding", "gzip").Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if got := req.Header.Get("Accept-Encoding"); got != "" {
+			t.Errorf("built request must not have Accept-Encoding, got => %s", got)
+		}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send %s", err)
+		}
+		defer CloseBody(res.Body)
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// transport decodes response of Accept-Encoding added by itself
+		if diff := cmp.Diff(string(body), content); diff != "" {
+			t.Errorf("invalid response, diff = %s", diff)
+		}
+	})
+
+	t.Run("accept_encoding_by_header", func(t *testing.T) {
+		var got []uint8
+		err := Get(server.URL).Client(httpClient).Header("Accept-Encoding", "gzip").URLParam("encoding", "gzip").
+			HandleBody(func(body []uint8) error {
+				got = body
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("failed to get %s", err)
+		}
+		if bytes.Equal(got, []byte(content)) {
+			t.Error("response must not be decoded if Accept-Encoding is set by Header")
+		}
+	})
+}
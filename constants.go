@@ -4,6 +4,10 @@ const (
 	get         requestMethod = "GET"
 	post                      = "POST"
 	put                       = "PUT"
+	del                       = "DELETE"
+	patch                     = "PATCH"
+	head                      = "HEAD"
+	options                   = "OPTIONS"
 	jsonContent contentType   = "application/json"
 	urlEncoded  contentType   = "application/x-www-form-urlencoded"
 	multipart   contentType   = "multipart/form-data"
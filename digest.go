@@ -0,0 +1,293 @@
+package gorest
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestAuth holds credentials and the last challenge of digest authentication (RFC 7616).
+// it is shared by requests of Client, so nonce is reused across requests.
+type digestAuth struct {
+	username string
+	password string
+
+	mu         sync.Mutex
+	challenge  *digestChallenge
+	nonceCount uint32
+}
+
+// digestChallenge is parameters of `WWW-Authenticate: Digest ...`
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	userhash  bool
+}
+
+func newDigestAuth(username, password string) *digestAuth {
+	return &digestAuth{username: username, password: password}
+}
+
+// wrap sends request with Authorization by the last challenge,
+// and if server responds 401 with new challenge, sends request once again.
+func (d *digestAuth) wrap(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		used, err := d.authorize(req)
+		if err != nil {
+			return nil, err
+		}
+		res, err := next.Do(req)
+		if err != nil || res.StatusCode != http.StatusUnauthorized || !isReplayable(req) {
+			return res, err
+		}
+
+		challenge, stale := parseDigestChallenge(res.Header.Values(`WWW-Authenticate`))
+		if challenge == nil {
+			return res, nil
+		}
+		if used != nil && !stale && used.nonce == challenge.nonce {
+			// credentials are rejected with valid nonce
+			return res, nil
+		}
+		d.update(challenge)
+
+		retryReq, err := rewindRequest(req)
+		if err != nil {
+			return res, nil
+		}
+		if _, err := d.authorize(retryReq); err != nil {
+			return res, nil
+		}
+		CloseBody(res.Body)
+		return next.Do(retryReq)
+	})
+}
+
+// update replaces challenge, and resets nonce count if nonce is changed
+func (d *digestAuth) update(challenge *digestChallenge) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.challenge == nil || d.challenge.nonce != challenge.nonce {
+		d.nonceCount = 0
+	}
+	d.challenge = challenge
+}
+
+// authorize sets Authorization header by the last challenge, and returns the challenge.
+// before the first challenge, request is sent without Authorization.
+func (d *digestAuth) authorize(req *http.Request) (*digestChallenge, error) {
+	d.mu.Lock()
+	challenge := d.challenge
+	if challenge == nil {
+		d.mu.Unlock()
+		return nil, nil
+	}
+	d.nonceCount++
+	nonceCount := d.nonceCount
+	d.mu.Unlock()
+
+	authorization, err := challenge.authorization(req, d.username, d.password, nonceCount)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(`Authorization`, authorization)
+	return challenge, nil
+}
+
+// authorization returns value of Authorization header for the challenge
+func (c *digestChallenge) authorization(req *http.Request, username, password string, nonceCount uint32) (string, error) {
+	newHash := digestHash(c.algorithm)
+	if newHash == nil {
+		return ``, fmt.Errorf(`unsupported digest algorithm %s`, c.algorithm)
+	}
+	h := func(s string) string {
+		hasher := newHash()
+		_, _ = hasher.Write([]byte(s))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	cnonce, err := newCnonce()
+	if err != nil {
+		return ``, err
+	}
+	nc := fmt.Sprintf(`%08x`, nonceCount)
+	uri := req.URL.RequestURI()
+
+	ha1 := h(username + `:` + c.realm + `:` + password)
+	if strings.HasSuffix(strings.ToLower(c.algorithm), `-sess`) {
+		ha1 = h(ha1 + `:` + c.nonce + `:` + cnonce)
+	}
+	ha2 := h(req.Method + `:` + uri)
+
+	var response string
+	if c.qop == `` {
+		response = h(ha1 + `:` + c.nonce + `:` + ha2)
+	} else {
+		response = h(strings.Join([]string{ha1, c.nonce, nc, cnonce, c.qop, ha2}, `:`))
+	}
+
+	if c.userhash {
+		username = h(username + `:` + c.realm)
+	}
+	params := []string{
+		`username=` + quoteDigest(username),
+		`realm=` + quoteDigest(c.realm),
+		`nonce=` + quoteDigest(c.nonce),
+		`uri=` + quoteDigest(uri),
+		`algorithm=` + c.algorithm,
+		`response=` + quoteDigest(response),
+	}
+	if c.opaque != `` {
+		params = append(params, `opaque=`+quoteDigest(c.opaque))
+	}
+	if c.qop != `` {
+		params = append(params, `qop=`+c.qop, `nc=`+nc, `cnonce=`+quoteDigest(cnonce))
+	}
+	if c.userhash {
+		params = append(params, `userhash=true`)
+	}
+	return `Digest ` + strings.Join(params, `, `), nil
+}
+
+// digestHash returns hash function of algorithm, or nil if it is unsupported
+func digestHash(algorithm string) func() hash.Hash {
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(algorithm), `-sess`)) {
+	case `MD5`:
+		return md5.New
+	case `SHA-256`:
+		return sha256.New
+	default:
+		return nil
+	}
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ``, err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func quoteDigest(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// parseDigestChallenge returns the strongest supported digest challenge in WWW-Authenticate headers,
+// and whether the nonce is stale
+func parseDigestChallenge(headers []string) (*digestChallenge, bool) {
+	var selected *digestChallenge
+	var stale bool
+	for _, header := range headers {
+		for _, challenge := range parseAuthChallenges(header) {
+			if !strings.EqualFold(challenge.scheme, `Digest`) {
+				continue
+			}
+			params := challenge.params
+			c := &digestChallenge{
+				realm:     params[`realm`],
+				nonce:     params[`nonce`],
+				opaque:    params[`opaque`],
+				algorithm: params[`algorithm`],
+				userhash:  strings.EqualFold(params[`userhash`], `true`),
+			}
+			if c.algorithm == `` {
+				c.algorithm = `MD5`
+			}
+			if c.nonce == `` || digestHash(c.algorithm) == nil {
+				continue
+			}
+			if qop, ok := params[`qop`]; ok {
+				for _, v := range strings.Split(qop, `,`) {
+					if strings.TrimSpace(v) == `auth` {
+						c.qop = `auth`
+					}
+				}
+				if c.qop == `` {
+					// auth-int only is unsupported
+					continue
+				}
+			}
+			// SHA-256 is preferred to MD5
+			if selected == nil || strings.HasPrefix(strings.ToUpper(c.algorithm), `SHA-256`) &&
+				!strings.HasPrefix(strings.ToUpper(selected.algorithm), `SHA-256`) {
+				selected = c
+				stale = strings.EqualFold(params[`stale`], `true`)
+			}
+		}
+	}
+	return selected, stale
+}
+
+// authChallenge is a challenge of WWW-Authenticate header
+type authChallenge struct {
+	scheme string
+	params map[string]string
+}
+
+// parseAuthChallenges parses challenges like `Digest realm="a", nonce="b", Basic realm="c"`
+func parseAuthChallenges(header string) []authChallenge {
+	var challenges []authChallenge
+	s := header
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == `` {
+			return challenges
+		}
+
+		token, rest := readAuthToken(s)
+		if token == `` {
+			return challenges
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		if strings.HasPrefix(rest, `=`) && len(challenges) != 0 {
+			// parameter of current challenge
+			value, remaining := readAuthValue(strings.TrimLeft(rest[1:], " \t"))
+			challenges[len(challenges)-1].params[strings.ToLower(token)] = value
+			s = remaining
+			continue
+		}
+		challenges = append(challenges, authChallenge{scheme: token, params: map[string]string{}})
+		s = rest
+	}
+}
+
+// readAuthToken reads token until separator
+func readAuthToken(s string) (string, string) {
+	i := strings.IndexAny(s, " \t,=\"")
+	if i == -1 {
+		return s, ``
+	}
+	return s[:i], s[i:]
+}
+
+// readAuthValue reads quoted string or token
+func readAuthValue(s string) (string, string) {
+	if !strings.HasPrefix(s, `"`) {
+		return readAuthToken(s)
+	}
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				value.WriteByte(s[i])
+			}
+		case '"':
+			return value.String(), s[i+1:]
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return value.String(), ``
+}
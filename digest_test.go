@@ -0,0 +1,165 @@
+package gorest
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// digestServer verifies digest authentication, and records count of challenges and nonce counts
+type digestServer struct {
+	algorithm string
+	newHash   func() hash.Hash
+	nonce     string
+
+	mu          sync.Mutex
+	challenges  int
+	nonceCounts []string
+}
+
+func (s *digestServer) h(v string) string {
+	hasher := s.newHash()
+	_, _ = hasher.Write([]byte(v))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func (s *digestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authorization := r.Header.Get("Authorization")
+	params := map[string]string{}
+	if challenges := parseAuthChallenges(authorization); len(challenges) == 1 && challenges[0].scheme == "Digest" {
+		params = challenges[0].params
+	}
+	ha1 := s.h("user:testrealm@example.com:pass")
+	ha2 := s.h(r.Method + ":" + params["uri"])
+	want := s.h(strings.Join([]string{ha1, s.nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+
+	if params["response"] == "" || params["response"] != want || params["nonce"] != s.nonce ||
+		params["opaque"] != "opaque-value" || params["uri"] != r.URL.RequestURI() {
+		s.challenges++
+		w.Header().Add("WWW-Authenticate", `Basic realm="testrealm@example.com"`)
+		w.Header().Add("WWW-Authenticate", `Digest realm="testrealm@example.com", qop="auth,auth-int", `+
+			`algorithm=`+s.algorithm+`, nonce="`+s.nonce+`", opaque="opaque-value"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.nonceCounts = append(s.nonceCounts, params["nc"])
+	_, _ = w.Write([]byte("success"))
+}
+
+func Test_client_DigestAuth(t *testing.T) {
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		newHash   func() hash.Hash
+	}{
+		{name: "md5", algorithm: "MD5", newHash: md5.New},
+		{name: "sha256", algorithm: "SHA-256", newHash: sha256.New},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &digestServer{algorithm: tt.algorithm, newHash: tt.newHash, nonce: "dcd98b7102dd2f0e8b11d0f600bfb0c093"}
+			server := httptest.NewTLSServer(handler)
+			defer server.Close()
+
+			api := New(server.URL, WithHTTPClient(httpClient), WithDigestAuth("user", "pass"))
+			for i := 0; i < 3; i++ {
+				err := api.Post().Path("/dir/index.html").URLParam("q", "1").
+					JSONString(`{"name":"gorest"}`).
+					HandleBody(func(body []uint8) error {
+						if string(body) != "success" {
+							t.Errorf("wrong response, got => %s", body)
+						}
+						return nil
+					})
+				if err != nil {
+					t.Fatalf("failed to post %s", err)
+				}
+			}
+
+			if handler.challenges != 1 {
+				t.Errorf("nonce must be reused, challenges => %d", handler.challenges)
+			}
+			if diff := cmp.Diff(handler.nonceCounts, []string{"00000001", "00000002", "00000003"}); diff != "" {
+				t.Errorf("invalid nonce counts, diff = %s", diff)
+			}
+		})
+	}
+}
+
+func Test_client_DigestAuth_invalid_password(t *testing.T) {
+	handler := &digestServer{algorithm: "MD5", newHash: md5.New, nonce: "nonce"}
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	_, err := Get(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		DigestAuth("user", "invalid").
+		ExpectStatus(http.StatusOK).
+		Execute()
+
+	var statusErr *InvalidStatusCodeError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("401 must be error, got => %v", err)
+	}
+	if handler.challenges != 2 {
+		t.Errorf("request must be sent only once again, challenges => %d", handler.challenges)
+	}
+}
+
+func Test_parseDigestChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		headers   []string
+		want      *digestChallenge
+		wantStale bool
+	}{
+		{
+			name: "sha256_is_preferred",
+			headers: []string{
+				`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=MD5, nonce="7ypf", opaque="FQhe"`,
+				`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, nonce="7ypf", opaque="FQhe"`,
+			},
+			want: &digestChallenge{realm: "http-auth@example.org", nonce: "7ypf", opaque: "FQhe", algorithm: "SHA-256", qop: "auth"},
+		},
+		{
+			name:      "stale_with_other_scheme",
+			headers:   []string{`Basic realm="a\"b", Digest realm="a", nonce="n", stale=TRUE`},
+			want:      &digestChallenge{realm: "a", nonce: "n", algorithm: "MD5"},
+			wantStale: true,
+		},
+		{
+			name:    "unsupported",
+			headers: []string{`Digest realm="a", nonce="n", qop="auth-int"`, `Digest realm="a", nonce="n", algorithm=SHA-512-256`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stale := parseDigestChallenge(tt.headers)
+			if diff := cmp.Diff(got, tt.want, cmp.AllowUnexported(digestChallenge{})); diff != "" {
+				t.Errorf("parseDigestChallenge() diff = %s", diff)
+			}
+			if stale != tt.wantStale {
+				t.Errorf("stale => %v, want => %v", stale, tt.wantStale)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package gorest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// defaultMaxErrorBodySize is default max size of response body captured by InvalidStatusCodeError
+const defaultMaxErrorBodySize = 64 * 1024
+
+// jsonMessageKeys are keys of json error response which have message
+var jsonMessageKeys = []string{`message`, `error_description`, `error`, `detail`, `title`}
+
+// InvalidStatusCodeError is returned when response has invalid status code
+type InvalidStatusCodeError struct {
+	StatusCode int
+	// ResponseBody is captured response body, it is limited by MaxErrorBody
+	// and redacted by RedactErrorBody.
+	ResponseBody []byte
+	// Truncated is true if ResponseBody is a part of response body
+	Truncated bool
+	Header    http.Header
+	Method    string
+	// URL is request url, password is redacted
+	URL string
+	// Model is response body decoded into the type registered by OnError,
+	// nil if not registered, status code is under 400 or failed to decode.
+	Model interface{}
+}
+
+func (i *InvalidStatusCodeError) Error() string {
+	if i.Method == `` && i.URL == `` {
+		return fmt.Sprintf("StatusCode: %d, responseBody: %v", i.StatusCode, i.summary())
+	}
+	return fmt.Sprintf("%s %s, StatusCode: %d, responseBody: %v",
+		i.Method, i.URL, i.StatusCode, i.summary())
+}
+
+// summary returns short description of response body by content type.
+// message field of json, or size of html.
+func (i *InvalidStatusCodeError) summary() string {
+	var mediaType string
+	if i.Header != nil {
+		mediaType, _, _ = mime.ParseMediaType(i.Header.Get(`Content-Type`))
+	}
+
+	switch {
+	case mediaType == `application/json` || strings.HasSuffix(mediaType, `+json`):
+		if message, ok := jsonMessage(i.ResponseBody); ok {
+			return message
+		}
+	case mediaType == `text/html`:
+		return fmt.Sprintf(`(html %d bytes)`, len(i.ResponseBody))
+	}
+
+	if i.Truncated {
+		return string(i.ResponseBody) + `...(truncated)`
+	}
+	return string(i.ResponseBody)
+}
+
+// jsonMessage finds message in json error response like
+// `{"message": "..."}` or `{"error": {"message": "..."}}`
+func jsonMessage(body []byte) (string, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ``, false
+	}
+	for _, key := range jsonMessageKeys {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		var message string
+		if err := json.Unmarshal(raw, &message); err == nil && message != `` {
+			return message, true
+		}
+		if nested, ok := jsonMessage(raw); ok {
+			return nested, true
+		}
+	}
+	return ``, false
+}
+
+// readErrorBody reads response body until max size
+func readErrorBody(body io.Reader, max int64) ([]byte, bool, error) {
+	captured, err := ioutil.ReadAll(io.LimitReader(body, max+1))
+	if int64(len(captured)) > max {
+		return captured[:max], true, err
+	}
+	return captured, false, err
+}
+
+// Unwrap returns Model if it implements error,
+// so errors.As finds both InvalidStatusCodeError and the error model.
+func (i *InvalidStatusCodeError) Unwrap() error {
+	if err, ok := i.Model.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// decodeErrorModel decodes body into a new value which has same type as model.
+// returns nil if body cannot be decoded.
+func decodeErrorModel(model interface{}, body []byte) interface{} {
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	out := reflect.New(modelType).Interface()
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,352 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testAPIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *testAPIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func Test_client_OnError(t *testing.T) {
+	var remoteURL string
+	{
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Request-Id", "abc")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"invalid_name","message":"name is required"}`))
+		}))
+		defer server.Close()
+		remoteURL = server.URL
+	}
+
+	model := &testAPIError{}
+	err := Post(remoteURL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Path("/tickets").
+		OnError(model).
+		JSONString(`{}`).
+		HandleBody(func(body []uint8) error {
+			t.Error("HandleBody() callback must not be called")
+			return nil
+		})
+
+	var apiErr *testAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error must be testAPIError, got => %v", err)
+	}
+	if diff := cmp.Diff(apiErr, &testAPIError{Code: "invalid_name", Message: "name is required"}); diff != "" {
+		t.Errorf("error model diff = %s", diff)
+	}
+	if apiErr == model {
+		t.Error("registered model must not be reused")
+	}
+
+	var statusErr *InvalidStatusCodeError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("error must be InvalidStatusCodeError, got => %v", err)
+	}
+	if statusErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid status code, got => %d", statusErr.StatusCode)
+	}
+	if statusErr.Method != http.MethodPost {
+		t.Errorf("invalid method, got => %s", statusErr.Method)
+	}
+	if statusErr.URL != remoteURL+"/tickets" {
+		t.Errorf("invalid url, got => %s", statusErr.URL)
+	}
+	if statusErr.Header.Get("X-Request-Id") != "abc" {
+		t.Errorf("invalid header, got => %v", statusErr.Header)
+	}
+}
+
+func Test_client_OnError_rejected_status(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantModel  bool
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			wantModel:  false,
+		},
+		{
+			name:       "redirect",
+			statusCode: http.StatusFound,
+			wantModel:  false,
+		},
+		{
+			name:       "client_error",
+			statusCode: http.StatusConflict,
+			wantModel:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(`{"code":"ok","message":"not an error"}`))
+			}))
+			defer server.Close()
+
+			err := Get(server.URL).
+				Client(&http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}}).
+				NoRedirect().
+				ExpectStatus(http.StatusCreated).
+				OnError(&testAPIError{}).
+				HandleBody(func(body []uint8) error { return nil })
+
+			var statusErr *InvalidStatusCodeError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("error must be InvalidStatusCodeError, got => %v", err)
+			}
+			if (statusErr.Model != nil) != tt.wantModel {
+				t.Errorf("invalid model, got => %v, wantModel %v", statusErr.Model, tt.wantModel)
+			}
+			if len(statusErr.ResponseBody) == 0 {
+				t.Error("response body must be captured")
+			}
+		})
+	}
+}
+
+func Test_decodeErrorModel(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		body  []byte
+		want  interface{}
+	}{
+		{
+			name:  "pointer_model",
+			model: &testAPIError{},
+			body:  []byte(`{"code":"c","message":"m"}`),
+			want:  &testAPIError{Code: "c", Message: "m"},
+		},
+		{
+			name:  "value_model",
+			model: testAPIError{},
+			body:  []byte(`{"code":"c","message":"m"}`),
+			want:  &testAPIError{Code: "c", Message: "m"},
+		},
+		{
+			name:  "invalid_body",
+			model: &testAPIError{},
+			body:  []byte(`<html></html>`),
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeErrorModel(tt.model, tt.body)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("decodeErrorModel() diff = %s", diff)
+			}
+		})
+	}
+}
+
+func TestInvalidStatusCodeError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *InvalidStatusCodeError
+		want string
+	}{
+		{
+			name: "plain",
+			err:  &InvalidStatusCodeError{StatusCode: 500, ResponseBody: []byte("internal error")},
+			want: "StatusCode: 500, responseBody: internal error",
+		},
+		{
+			name: "request",
+			err: &InvalidStatusCodeError{
+				StatusCode:   404,
+				ResponseBody: []byte("not found"),
+				Method:       "GET",
+				URL:          "https://sample.com/users",
+			},
+			want: "GET https://sample.com/users, StatusCode: 404, responseBody: not found",
+		},
+		{
+			name: "truncated",
+			err:  &InvalidStatusCodeError{StatusCode: 500, ResponseBody: []byte("internal"), Truncated: true},
+			want: "StatusCode: 500, responseBody: internal...(truncated)",
+		},
+		{
+			name: "json_message",
+			err: &InvalidStatusCodeError{
+				StatusCode:   400,
+				ResponseBody: []byte(`{"message":"name is required","token":"secret"}`),
+				Header:       http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			},
+			want: "StatusCode: 400, responseBody: name is required",
+		},
+		{
+			name: "nested_json_message",
+			err: &InvalidStatusCodeError{
+				StatusCode:   400,
+				ResponseBody: []byte(`{"error":{"code":1,"message":"invalid"}}`),
+				Header:       http.Header{"Content-Type": {"application/problem+json"}},
+			},
+			want: "StatusCode: 400, responseBody: invalid",
+		},
+		{
+			name: "json_without_message",
+			err: &InvalidStatusCodeError{
+				StatusCode:   400,
+				ResponseBody: []byte(`{"code":1}`),
+				Header:       http.Header{"Content-Type": {"application/json"}},
+			},
+			want: `StatusCode: 400, responseBody: {"code":1}`,
+		},
+		{
+			name: "html",
+			err: &InvalidStatusCodeError{
+				StatusCode:   502,
+				ResponseBody: []byte(`<html><body>Bad Gateway</body></html>`),
+				Header:       http.Header{"Content-Type": {"text/html"}},
+			},
+			want: "StatusCode: 502, responseBody: (html 37 bytes)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_MaxErrorBody(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"code":"expired","access_token":"secret","message":"token is expired"}`))
+	}))
+	defer server.Close()
+
+	base := New(server.URL,
+		WithHTTPClient(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}),
+		WithErrorModel(&testAPIError{}),
+	)
+
+	t.Run("truncated", func(t *testing.T) {
+		err := base.Get().
+			MaxErrorBody(10).
+			HandleBody(func(body []uint8) error { return nil })
+
+		var statusErr *InvalidStatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("error must be InvalidStatusCodeError, got => %v", err)
+		}
+		if diff := cmp.Diff(string(statusErr.ResponseBody), `{"code":"e`); diff != "" {
+			t.Errorf("invalid response body, diff = %s", diff)
+		}
+		if !statusErr.Truncated {
+			t.Error("Truncated must be true")
+		}
+		if statusErr.Model != nil {
+			t.Error("truncated body must not be decoded")
+		}
+	})
+
+	t.Run("truncated_and_redacted", func(t *testing.T) {
+		err := base.Get().
+			MaxErrorBody(40).
+			RedactErrorBody(RedactJSONFields("access_token")).
+			HandleBody(func(body []uint8) error { return nil })
+
+		var statusErr *InvalidStatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("error must be InvalidStatusCodeError, got => %v", err)
+		}
+		if !statusErr.Truncated {
+			t.Error("Truncated must be true")
+		}
+		if strings.Contains(err.Error(), "secret") {
+			t.Errorf("error must be redacted, got => %s", err)
+		}
+		if diff := cmp.Diff(string(statusErr.ResponseBody), `{"code":"expired","access_token":"[REDACTED]"`); diff != "" {
+			t.Errorf("invalid response body, diff = %s", diff)
+		}
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		err := base.Get().
+			RedactErrorBody(RedactJSONFields("access_token")).
+			HandleBody(func(body []uint8) error { return nil })
+
+		var statusErr *InvalidStatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("error must be InvalidStatusCodeError, got => %v", err)
+		}
+		if strings.Contains(string(statusErr.ResponseBody), "secret") {
+			t.Errorf("response body must be redacted, got => %s", statusErr.ResponseBody)
+		}
+		if strings.Contains(err.Error(), "secret") {
+			t.Errorf("error must be redacted, got => %s", err)
+		}
+		var apiErr *testAPIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "expired" {
+			t.Errorf("error model must be decoded, got => %v", apiErr)
+		}
+	})
+}
+
+func TestRedactJSONFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		body   string
+		want   string
+	}{
+		{
+			name:   "nested",
+			fields: []string{"password", "Token"},
+			body:   `{"user":{"name":"foo","password":"bar"},"items":[{"token":"x"}],"id":12345678901234567890}`,
+			want:   `{"id":12345678901234567890,"items":[{"token":"[REDACTED]"}],"user":{"name":"foo","password":"[REDACTED]"}}`,
+		},
+		{
+			name:   "not_json",
+			fields: []string{"password"},
+			body:   `password=bar`,
+			want:   `password=bar`,
+		},
+		{
+			name:   "truncated_json",
+			fields: []string{"password", "token"},
+			body:   `{"user":{"name":"foo","Password":"bar"},"token":"abc\"de`,
+			want:   `{"user":{"name":"foo","Password":"[REDACTED]"},"token":"[REDACTED]"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RedactJSONFields(tt.fields...)([]byte(tt.body))
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Errorf("RedactJSONFields() diff = %s", diff)
+			}
+		})
+	}
+}
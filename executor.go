@@ -2,6 +2,7 @@ package gorest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,21 +10,161 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
-// Execute executes api and return result, error
+// Execute executes api and return result, error.
+// status code is validated only if status policy like ExpectStatus is set.
 func (cli *client) Execute() (*http.Response, error) {
-	req, err := cli.buildRequest()
+	return cli.ExecuteContext(cli.context())
+}
+
+// ExecuteContext executes api with ctx and return result, error.
+// ctx is used until response body is closed.
+func (cli *client) ExecuteContext(ctx context.Context) (*http.Response, error) {
+	req, err := cli.buildRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cli.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
+	if cli.hasStatusPolicy() {
+		if err := cli.handleByStatusCode(res); err != nil {
+			CloseBody(res.Body)
+			return nil, err
+		}
+	}
+	return res, nil
+}
 
-	return cli.doRequest(req)
+// Build builds request without sending it
+func (cli *client) Build() (*http.Request, error) {
+	return cli.BuildContext(cli.context())
 }
 
+// BuildContext is same as Build, but request has ctx
+func (cli *client) BuildContext(ctx context.Context) (*http.Request, error) {
+	req, err := cli.buildRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cli.uploadProgress != nil {
+		watchUploadProgress(req, cli.uploadProgress)
+	}
+	return req, nil
+}
+
+// HandleBody executes api, validates status code and passes response body to f
 func (cli *client) HandleBody(f func(body []uint8) error) error {
-	req, err := cli.buildRequest()
+	return cli.HandleBodyContext(cli.context(), f)
+}
+
+// HandleBodyContext is same as HandleBody, but executes api with ctx
+func (cli *client) HandleBodyContext(ctx context.Context, f func(body []uint8) error) error {
+	return cli.handle(ctx, func(res *http.Response) error {
+		if cli.method == head {
+			// HEAD response never has body
+			return f([]uint8{})
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return f(body)
+	})
+}
+
+// DecodeJSON executes api, validates status code and decodes response body into out.
+// out must be a pointer. Empty body (ex. 204 No Content) leaves out as it is.
+func (cli *client) DecodeJSON(out interface{}) error {
+	return cli.decodeJSON(out, false)
+}
+
+// DecodeJSONStrict is same as DecodeJSON, but returns error
+// if response body has fields which out does not have.
+func (cli *client) DecodeJSONStrict(out interface{}) error {
+	return cli.decodeJSON(out, true)
+}
+
+func (cli *client) decodeJSON(out interface{}, disallowUnknownFields bool) error {
+	return cli.handle(cli.context(), func(res *http.Response) error {
+		if cli.method == head {
+			return nil
+		}
+		return decodeBody(res, out, disallowUnknownFields)
+	})
+}
+
+// HandleStream executes api, validates status code and passes response body reader to f.
+// response body is closed after f.
+func (cli *client) HandleStream(f func(r io.Reader) error) error {
+	return cli.handle(cli.context(), func(res *http.Response) error {
+		body := io.Reader(res.Body)
+		if cli.downloadProgress != nil {
+			total := res.ContentLength
+			if total < 0 {
+				total = -1
+			}
+			body = newProgressReader(res.Body, total, cli.downloadProgress)
+		}
+		return f(body)
+	})
+}
+
+// DownloadTo executes api, validates status code and copies response body into w
+func (cli *client) DownloadTo(w io.Writer) error {
+	return cli.HandleStream(func(r io.Reader) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// DownloadFile executes api, validates status code and saves response body as file.
+// body is written into temporary file in the same directory, and renamed to path after completion,
+// so path never has incomplete content.
+func (cli *client) DownloadFile(path string) error {
+	return cli.HandleStream(func(r io.Reader) error {
+		return writeFileAtomically(path, r, 0644)
+	})
+}
+
+// writeFileAtomically writes r into temporary file which has perm, and renames it to path
+func writeFileAtomically(path string, r io.Reader, perm os.FileMode) (err error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), `.`+filepath.Base(path)+`.*.tmp`)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// handle executes api, applies response handler, validates status code
+// and passes response to f. Response body is closed after f.
+func (cli *client) handle(ctx context.Context, f func(res *http.Response) error) error {
+	req, err := cli.buildRequest(ctx)
 	if err != nil {
 		return err
 	}
@@ -45,11 +186,7 @@ func (cli *client) HandleBody(f func(body []uint8) error) error {
 	if err := cli.handleByStatusCode(res); err != nil {
 		return err
 	}
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-	return f(body)
+	return f(res)
 }
 
 func CloseBody(body io.ReadCloser) {
@@ -58,131 +195,256 @@ func CloseBody(body io.ReadCloser) {
 }
 
 func (cli *client) handleByStatusCode(res *http.Response) error {
-	if res.StatusCode >= 400 {
-		var responseBody []byte
-		if body, err := ioutil.ReadAll(res.Body); err == nil {
-			responseBody = body
+	if !cli.isValidStatus(res.StatusCode) {
+		statusErr := &InvalidStatusCodeError{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Method:     string(cli.method),
 		}
-		return &InvalidStatusCodeError{
-			StatusCode:   res.StatusCode,
-			ResponseBody: responseBody,
+		if res.Request != nil {
+			statusErr.Method = res.Request.Method
+			statusErr.URL = res.Request.URL.Redacted()
 		}
+		if cli.method == head {
+			return statusErr
+		}
+
+		maxSize := cli.maxErrorBodySize
+		if maxSize <= 0 {
+			maxSize = defaultMaxErrorBodySize
+		}
+		responseBody, truncated, err := readErrorBody(res.Body, maxSize)
+		if err != nil {
+			responseBody, truncated = nil, false
+		}
+		// body of rejected success or redirect is not an error response
+		if cli.errorModel != nil && res.StatusCode >= 400 && len(responseBody) != 0 && !truncated {
+			statusErr.Model = decodeErrorModel(cli.errorModel, responseBody)
+		}
+		if cli.errorBodyRedactor != nil && len(responseBody) != 0 {
+			responseBody = cli.errorBodyRedactor(responseBody)
+		}
+		statusErr.ResponseBody = responseBody
+		statusErr.Truncated = truncated
+		return statusErr
 	}
 	return nil
 }
 
-type InvalidStatusCodeError struct {
-	StatusCode   int
-	ResponseBody []byte
-}
-
-func (i *InvalidStatusCodeError) Error() string {
-	return fmt.Sprintf("StatusCode: %d, responseBody: %v", i.StatusCode, string(i.ResponseBody))
+// context returns context set by Context, or background
+func (cli *client) context() context.Context {
+	if cli.ctx == nil {
+		return context.Background()
+	}
+	return cli.ctx
 }
 
-func (cli *client) buildRequest() (*http.Request, error) {
-	endpoint := concat(cli.baseURL, strings.Join(cli.paths, ``))
-	urlParamString := strings.Join(cli.urlParams, `&`)
-	if urlParamString != `` {
-		endpoint = join(endpoint, urlParamString, `?`)
+func (cli *client) buildRequest(ctx context.Context) (*http.Request, error) {
+	endpoint, err := cli.buildURL()
+	if err != nil {
+		return nil, err
 	}
 
-	body, err := cli.buildParams()
+	body, bodyContentType, err := cli.buildParams()
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest(string(cli.method), endpoint, body)
+	body, err = cli.compress(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set(`Content-Type`, string(cli.contentType))
-	for key, val := range cli.headers {
-		req.Header.Set(key, val)
+	req, err := http.NewRequestWithContext(ctx, string(cli.method), endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if stream, ok := body.(*multipartStream); ok && stream.size >= 0 {
+		req.ContentLength = stream.size
 	}
 
-	if cli.username != nil && cli.password != nil {
-		req.SetBasicAuth(*cli.username, *cli.password)
+	if body != nil && bodyContentType != notSet {
+		req.Header.Set(`Content-Type`, string(bodyContentType))
+	}
+	if body != nil && cli.compression != `` {
+		req.Header.Set(`Content-Encoding`, string(cli.compression))
+	}
+	for key, values := range cli.headers {
+		req.Header[key] = append([]string{}, values...)
+	}
+	for _, cookie := range cli.cookies {
+		req.AddCookie(cookie)
 	}
 
+	if err := cli.setAuthorization(req); err != nil {
+		return nil, err
+	}
+	if err := cli.sign(req); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
-func (cli *client) buildParams() (io.Reader, error) {
+// buildURL joins base url and paths, merges query parameters with query of base url
+func (cli *client) buildURL() (string, error) {
+	paths := make([]string, 0, len(cli.paths))
+	for _, path := range cli.paths {
+		expanded, err := expandPath(path, cli.pathParams)
+		if err != nil {
+			return ``, err
+		}
+		paths = append(paths, expanded)
+	}
+	endpoint := joinPaths(cli.baseURL, paths)
+
+	query := url.Values{}
+	for key, values := range cli.query {
+		query[key] = append(query[key], values...)
+	}
+	for _, v := range cli.queryStructs {
+		values, err := encodeQueryStruct(v)
+		if err != nil {
+			return ``, err
+		}
+		for key, value := range values {
+			query[key] = append(query[key], value...)
+		}
+	}
+	if len(query) == 0 {
+		return endpoint, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ``, err
+	}
+	merged := u.Query()
+	for key, values := range query {
+		merged[key] = append(merged[key], values...)
+	}
+	u.RawQuery = merged.Encode()
+	return u.String(), nil
+}
+
+// buildParams returns request body and its content type.
+// it never modifies cli, so request can be built repeatedly.
+func (cli *client) buildParams() (io.Reader, contentType, error) {
+	params := cli.params
 	if cli.hasJsonStruct {
-		var err error
-		cli.params, err = json.Marshal(cli.params)
+		marshaled, err := json.Marshal(cli.params)
 		if err != nil {
-			return nil, err
+			return nil, notSet, err
 		}
+		params = marshaled
 	}
 
 	switch cli.contentType {
 	case jsonContent:
-		if cli.params == nil {
-			return nil, nil
+		if params == nil {
+			return nil, jsonContent, nil
 		}
-		jsonBytes, ok := cli.params.([]byte)
+		jsonBytes, ok := params.([]byte)
 		if !ok {
 			// JSONStruct can receive invalid data...
-			return nil, errors.New("invalid body")
+			return nil, notSet, errors.New("invalid body")
 		}
-		return bytes.NewBuffer(jsonBytes), nil
+		return bytes.NewBuffer(jsonBytes), jsonContent, nil
 	case urlEncoded:
-		if cli.params == nil {
-			return nil, nil
+		if params == nil {
+			return nil, urlEncoded, nil
 		}
 		if cli.hasRawFormUrlEncoded {
-			urlEncodedString, ok := cli.params.(string)
+			urlEncodedString, ok := params.(string)
 			if !ok {
 				// this error never occur
-				return nil, errors.New("url encoded string cannot be converted bytes")
+				return nil, notSet, errors.New("url encoded string cannot be converted bytes")
 			}
-			return strings.NewReader(urlEncodedString), nil
+			return strings.NewReader(urlEncodedString), urlEncoded, nil
 		}
 
-		notEscaped, ok := cli.params.(string)
+		notEscaped, ok := params.(string)
 		if ok {
-			return strings.NewReader(url.QueryEscape(notEscaped)), nil
+			return strings.NewReader(url.QueryEscape(notEscaped)), urlEncoded, nil
 		}
 
-		values, ok := cli.params.(url.Values)
+		values, ok := params.(url.Values)
 		if !ok {
-			return nil, errors.New(`invalid request body parameters`)
+			return nil, notSet, errors.New(`invalid request body parameters`)
 		}
-		return strings.NewReader(values.Encode()), nil
+		return strings.NewReader(values.Encode()), urlEncoded, nil
 	case notSet:
 		if len(cli.multipartSettings) == 0 {
-			return nil, nil
+			return nil, notSet, nil
+		}
+		if cli.multipartStreaming {
+			return cli.setupStreamingMultipartRequest()
 		}
-		body, err := cli.setupMultipartRequest()
+		body, bodyContentType, err := cli.setupMultipartRequest()
 		if err != nil {
-			return nil, fmt.Errorf(`invalid request body parameters, %s`, err)
+			return nil, notSet, fmt.Errorf(`invalid request body parameters, %s`, err)
 		}
-		return body, nil
+		return body, bodyContentType, nil
 	default:
-		return nil, errors.New(`unsupported content type for request body`)
+		return nil, notSet, errors.New(`unsupported content type for request body`)
 	}
 }
 
 // Do sends an HTTP request and returns an HTTP response
 func (cli *client) doRequest(req *http.Request) (*http.Response, error) {
-	if cli.client == nil {
-		cli.client = http.DefaultClient
-	}
-	return cli.client.Do(req)
-}
-
-//
-//// decodeBody decode response body and stores it in the value pointed to by out
-//func decodeBody(resp *http.Response, out interface{}, equals io.WriteCloser) error {
-//	defer resp.Body.Close()
-//	// Symmetric API Testing
-//	if equals != nil {
-//		resp.Body = ioutil.NopCloser(io.TeeReader(resp.Body, equals))
-//		defer equals.Close()
-//	}
-//	decoder := json.NewDecoder(resp.Body)
-//	return decoder.Decode(out)
-//}
+	doer := Doer(DoerFunc(cli.send))
+	if cli.decodesResponse() {
+		doer = decodeResponse(doer)
+	}
+	if cli.logger != nil {
+		doer = cli.logger.wrap(doer)
+	}
+	if cli.digestAuth != nil {
+		doer = cli.digestAuth.wrap(doer)
+	}
+	if cli.tokenSource != nil {
+		doer = retryUnauthorized(doer, cli.tokenSource)
+	}
+	return cli.withTimeout(req, chainMiddlewares(doer, cli.middlewares))
+}
+
+// send sends req by http client within Timeouts, retries by policy
+func (cli *client) send(req *http.Request) (*http.Response, error) {
+	httpClient := cli.client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if cli.noRedirect {
+		// never modify shared client
+		copied := *httpClient
+		copied.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		httpClient = &copied
+	}
+	do := func(req *http.Request) (*http.Response, error) {
+		if cli.uploadProgress != nil {
+			// only sending body is reported, not reading it for log or signature
+			req = withUploadProgress(req, cli.uploadProgress)
+		}
+		return cli.attempt(req, httpClient.Do)
+	}
+	if cli.retryPolicy != nil {
+		return cli.retryPolicy.do(req, do)
+	}
+	return do(req)
+}
+
+// decodeBody decode response body and stores it in the value pointed to by out
+func decodeBody(res *http.Response, out interface{}, disallowUnknownFields bool) error {
+	decoder := json.NewDecoder(res.Body)
+	if disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(out); err != nil {
+		if err == io.EOF {
+			// empty body
+			return nil
+		}
+		return err
+	}
+	return nil
+}
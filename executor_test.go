@@ -2,16 +2,23 @@ package gorest
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"io"
 	"io/ioutil"
+	mineMultipart "mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -228,7 +235,7 @@ func Test_client_Execute_simple_multipart_file_post(t *testing.T) {
 			}
 			defer file.Close()
 
-			if header.Filename != fileName {
+			if header.Filename != filepath.Base(fileName) {
 				t.Fatalf("invalid file name  got = %s", header.Filename)
 			}
 
@@ -403,7 +410,7 @@ func Test_client_buildParams(t *testing.T) {
 				hasJsonStruct:        tt.fields.hasJsonStruct,
 				hasRawFormUrlEncoded: tt.fields.hasRawFormUrlEncoded,
 			}
-			got, err := cli.buildParams()
+			got, _, err := cli.buildParams()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildParams() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -414,3 +421,948 @@ func Test_client_buildParams(t *testing.T) {
 		})
 	}
 }
+
+func Test_client_Execute_json_patch(t *testing.T) {
+	var remoteURL string
+	{
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPatch {
+				t.Errorf("invalid method %s", r.Method)
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("failed to read body, %s", err)
+			}
+			if diff := cmp.Diff(
+				body,
+				[]byte("{\"Name\":\"name\"}"),
+			); diff != "" {
+				t.Errorf("invalid patchBody, diff = %s", diff)
+			}
+			if _, err := w.Write([]byte("success")); err != nil {
+				t.Errorf("failed to write response %s", err)
+			}
+		}))
+		defer server.Close()
+		remoteURL = server.URL
+	}
+
+	response, err := Patch(remoteURL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		JSONStruct(struct{ Name string }{Name: "name"}).
+		Execute()
+	if err != nil {
+		t.Errorf("failed to patch %s", err)
+		return
+	}
+	defer CloseBody(response.Body)
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Errorf("failed to read response %s", err)
+	}
+	if !reflect.DeepEqual(body, []byte("success")) {
+		t.Fatalf("wrong response, got => %s", body)
+	}
+}
+
+func Test_client_Execute_headers(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := http.Header{}
+		for _, key := range []string{"Accept", "Content-Type", "User-Agent"} {
+			if values, ok := r.Header[key]; ok {
+				header[key] = values
+			}
+		}
+		if err := json.NewEncoder(w).Encode(header); err != nil {
+			t.Errorf("failed to write response %s", err)
+		}
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	tests := []struct {
+		name    string
+		request Executor
+		want    http.Header
+	}{
+		{
+			name:    "no_content_type_without_body",
+			request: Get(server.URL).Client(httpClient).Accept("application/json", "text/plain").UserAgent("gorest"),
+			want:    http.Header{"Accept": {"application/json", "text/plain"}, "User-Agent": {"gorest"}},
+		},
+		{
+			name:    "no_content_type_without_json_body",
+			request: Post(server.URL).Client(httpClient).UserAgent("gorest").JSON(nil),
+			want:    http.Header{"User-Agent": {"gorest"}},
+		},
+		{
+			name:    "no_content_type_with_empty_json_string",
+			request: Post(server.URL).Client(httpClient).UserAgent("gorest").JSONString(""),
+			want:    http.Header{"User-Agent": {"gorest"}},
+		},
+		{
+			name:    "content_type_of_body",
+			request: Post(server.URL).Client(httpClient).UserAgent("gorest").JSONString(`{}`),
+			want:    http.Header{"Content-Type": {"application/json"}, "User-Agent": {"gorest"}},
+		},
+		{
+			name: "content_type_override",
+			request: Patch(server.URL).Client(httpClient).UserAgent("gorest").
+				ContentType("application/merge-patch+json").JSONString(`{}`),
+			want: http.Header{"Content-Type": {"application/merge-patch+json"}, "User-Agent": {"gorest"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			if err := tt.request.DecodeJSON(&got); err != nil {
+				t.Fatalf("failed to execute %s", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("invalid request headers, diff = %s", diff)
+			}
+		})
+	}
+}
+
+func Test_client_Build(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	t.Run("json", func(t *testing.T) {
+		req, err := Post("https://sample.com").Path("/users/{id}").PathParam("id", "1").
+			URLParam("q", "a").BearerToken("token").
+			JSONStruct(struct{ Name string }{Name: "name"}).
+			BuildContext(ctx)
+		if err != nil {
+			t.Fatalf("BuildContext() error = %v", err)
+		}
+		if req.Context().Value(ctxKey{}) != "value" {
+			t.Error("request must have ctx")
+		}
+		if diff := cmp.Diff(req.Method+" "+req.URL.String(), "POST https://sample.com/users/1?q=a"); diff != "" {
+			t.Errorf("invalid endpoint, diff = %s", diff)
+		}
+		if diff := cmp.Diff(req.Header.Get("Authorization"), "Bearer token"); diff != "" {
+			t.Errorf("invalid Authorization, diff = %s", diff)
+		}
+		if diff := cmp.Diff(req.Header.Get("Content-Type"), "application/json"); diff != "" {
+			t.Errorf("invalid Content-Type, diff = %s", diff)
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(body), `{"Name":"name"}`); diff != "" {
+			t.Errorf("invalid body, diff = %s", diff)
+		}
+		if req.ContentLength != int64(len(body)) || req.GetBody == nil {
+			t.Error("body must have length and can be sent again")
+		}
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		req, err := Post("https://sample.com").
+			MultipartData("key", strings.NewReader("value"), true).
+			Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if err := req.ParseMultipartForm(1024); err != nil {
+			t.Fatalf("body must be multipart with boundary of Content-Type, %s", err)
+		}
+		if diff := cmp.Diff(req.MultipartForm.Value["key"], []string{"value"}); diff != "" {
+			t.Errorf("invalid multipart body, diff = %s", diff)
+		}
+	})
+
+	t.Run("execute_after_build", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("failed to read file %s", err)
+				return
+			}
+			defer file.Close()
+			_, _ = io.Copy(w, file)
+		}))
+		defer server.Close()
+
+		file, err := os.Open("testdata/sample.golden")
+		if err != nil {
+			t.Fatalf("cannot open file %q: %v", "testdata/sample.golden", err)
+		}
+		defer file.Close()
+
+		request := Post(server.URL).
+			Client(&http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}).
+			MultipartData("file", file, false)
+		req, err := request.Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if _, err := ioutil.ReadAll(req.Body); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := request.AsCurl(); err != nil {
+			t.Fatalf("AsCurl() error = %v", err)
+		}
+
+		// Build and AsCurl never consume the file
+		err = request.HandleBody(func(body []uint8) error {
+			if diff := cmp.Diff(string(body), "header1,header2\nvalue1,value2\n"); diff != "" {
+				t.Errorf("invalid response, diff = %s", diff)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to post %s", err)
+		}
+	})
+
+	t.Run("execute_after_build_streaming", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.FormValue("key")))
+		}))
+		defer server.Close()
+
+		request := Post(server.URL).
+			Client(&http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}).
+			MultipartData("key", io.MultiReader(strings.NewReader("value")), false).
+			StreamMultipart(false)
+		req, err := request.Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		// built request is discarded without reading body
+		_ = req.Body.Close()
+		command, err := request.AsCurl()
+		if err != nil {
+			t.Fatalf("AsCurl() error = %v", err)
+		}
+		if !strings.HasSuffix(command, "--data-binary @-") {
+			t.Errorf("streaming body must not be read, got => %s", command)
+		}
+
+		err = request.HandleBody(func(body []uint8) error {
+			if diff := cmp.Diff(string(body), "value"); diff != "" {
+				t.Errorf("invalid response, diff = %s", diff)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to post %s", err)
+		}
+	})
+
+	t.Run("send_by_other_client", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Header.Get("X-Api-Key")))
+		}))
+		defer server.Close()
+
+		req, err := Get(server.URL).Header("X-Api-Key", "token").Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("failed to send %s", err)
+		}
+		defer CloseBody(res.Body)
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(body), "token"); diff != "" {
+			t.Errorf("invalid response, diff = %s", diff)
+		}
+	})
+}
+
+func Test_client_Execute_custom_method_urlEncoded(t *testing.T) {
+	var remoteURL string
+	{
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "PURGE" {
+				t.Errorf("invalid method %s", r.Method)
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("failed to read body, %s", err)
+			}
+			if diff := cmp.Diff(
+				body,
+				[]byte("key=value"),
+			); diff != "" {
+				t.Errorf("invalid purgeBody, diff = %s", diff)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+		remoteURL = server.URL
+	}
+
+	response, err := Method("PURGE", remoteURL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		URLEncoded("key", "value").
+		Execute()
+	if err != nil {
+		t.Errorf("failed to purge %s", err)
+		return
+	}
+	defer CloseBody(response.Body)
+
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf("wrong status code, got => %d", response.StatusCode)
+	}
+}
+
+func Test_client_HandleBody_head(t *testing.T) {
+	var remoteURL string
+	{
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead {
+				t.Errorf("invalid method %s", r.Method)
+			}
+			w.Header().Set("X-Total-Count", "3")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		remoteURL = server.URL
+	}
+
+	called := false
+	err := Head(remoteURL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		HandleBody(func(body []uint8) error {
+			called = true
+			if len(body) != 0 {
+				t.Errorf("body must be empty, got => %s", body)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("failed to head %s", err)
+	}
+	if !called {
+		t.Fatal("HandleBody() callback not called")
+	}
+}
+
+func Test_client_ExecuteContext_canceled(t *testing.T) {
+	release := make(chan struct{})
+	var remoteURL string
+	{
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+		remoteURL = server.URL
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := Get(remoteURL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		ExecuteContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error must be context.Canceled, got => %v", err)
+	}
+}
+
+func Test_client_HandleBody_context_deadline(t *testing.T) {
+	release := make(chan struct{})
+	var remoteURL string
+	{
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+		remoteURL = server.URL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := Get(remoteURL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Context(ctx).
+		HandleBody(func(body []uint8) error {
+			t.Error("HandleBody() callback must not be called")
+			return nil
+		})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error must be context.DeadlineExceeded, got => %v", err)
+	}
+}
+
+func Test_client_DecodeJSON(t *testing.T) {
+	type ticket struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		response   string
+		strict     bool
+		want       ticket
+		wantErr    bool
+	}{
+		{
+			name:       "decode",
+			statusCode: http.StatusOK,
+			response:   `{"id":"1","name":"foo","extra":true}`,
+			want:       ticket{ID: "1", Name: "foo"},
+		},
+		{
+			name:       "strict_unknown_field",
+			statusCode: http.StatusOK,
+			response:   `{"id":"1","name":"foo","extra":true}`,
+			strict:     true,
+			want:       ticket{ID: "1", Name: "foo"},
+			wantErr:    true,
+		},
+		{
+			name:       "strict_known_fields",
+			statusCode: http.StatusOK,
+			response:   `{"id":"1","name":"foo"}`,
+			strict:     true,
+			want:       ticket{ID: "1", Name: "foo"},
+		},
+		{
+			name:       "empty_body",
+			statusCode: http.StatusNoContent,
+			response:   ``,
+			want:       ticket{},
+		},
+		{
+			name:       "invalid_status_code",
+			statusCode: http.StatusNotFound,
+			response:   `{"id":"1","name":"foo"}`,
+			want:       ticket{},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			executor := Get(server.URL).
+				Client(&http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}})
+
+			var got ticket
+			var err error
+			if tt.strict {
+				err = executor.DecodeJSONStrict(&got)
+			} else {
+				err = executor.DecodeJSON(&got)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("DecodeJSON() diff = %s", diff)
+			}
+		})
+	}
+}
+
+func Test_client_buildURL(t *testing.T) {
+	type search struct {
+		Query string   `url:"q"`
+		Tags  []string `url:"tag,omitempty"`
+		Page  int      `url:"page,omitempty"`
+	}
+
+	tests := []struct {
+		name    string
+		cli     *client
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no_query",
+			cli: &client{
+				baseURL: "https://sample.com",
+				paths:   []string{"/users"},
+			},
+			want: "https://sample.com/users",
+		},
+		{
+			name: "path_params",
+			cli: &client{
+				baseURL:    "https://sample.com/",
+				paths:      []string{"/repos/{owner}/{repo}", "/issues"},
+				pathParams: map[string]string{"owner": "izumix03", "repo": "go/rest"},
+				query:      url.Values{"state": []string{"open"}},
+			},
+			want: "https://sample.com/repos/izumix03/go%2Frest/issues?state=open",
+		},
+		{
+			name: "braces_in_path_args",
+			cli: (&client{
+				baseURL: "https://sample.com",
+			}).Path("/items/%s", "{draft}").(*client),
+			want: "https://sample.com/items/%7Bdraft%7D",
+		},
+		{
+			name: "missing_path_param",
+			cli: &client{
+				baseURL: "https://sample.com",
+				paths:   []string{"/repos/{owner}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "escaped_query",
+			cli: &client{
+				baseURL: "https://sample.com",
+				paths:   []string{"/users"},
+				query:   url.Values{"name": []string{"a&b c+d"}},
+			},
+			want: "https://sample.com/users?name=a%26b+c%2Bd",
+		},
+		{
+			name: "repeated_key",
+			cli: &client{
+				baseURL: "https://sample.com",
+				query:   url.Values{"id": []string{"1", "2"}},
+			},
+			want: "https://sample.com?id=1&id=2",
+		},
+		{
+			name: "merge_base_url_query",
+			cli: &client{
+				baseURL: "https://sample.com/users?sort=name&id=0",
+				query:   url.Values{"id": []string{"1"}},
+			},
+			want: "https://sample.com/users?id=0&id=1&sort=name",
+		},
+		{
+			name: "query_struct",
+			cli: &client{
+				baseURL:      "https://sample.com/search",
+				query:        url.Values{"lang": []string{"go"}},
+				queryStructs: []interface{}{search{Query: "rest client", Tags: []string{"a", "b"}}},
+			},
+			want: "https://sample.com/search?lang=go&q=rest+client&tag=a&tag=b",
+		},
+		{
+			name: "invalid_query_struct",
+			cli: &client{
+				baseURL:      "https://sample.com/search",
+				queryStructs: []interface{}{"not struct"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cli.buildURL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("buildURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_Execute_streaming_multipart_post(t *testing.T) {
+	content := "header1,header2\nvalue1,value2\n"
+
+	tests := []struct {
+		name                 string
+		reader               func() io.Reader
+		computeContentLength bool
+		wantChunked          bool
+	}{
+		{
+			name:                 "known_size",
+			reader:               func() io.Reader { return strings.NewReader(content) },
+			computeContentLength: true,
+			wantChunked:          false,
+		},
+		{
+			name:                 "unknown_size",
+			reader:               func() io.Reader { return io.MultiReader(strings.NewReader(content)) },
+			computeContentLength: true,
+			wantChunked:          true,
+		},
+		{
+			name:                 "content_length_not_required",
+			reader:               func() io.Reader { return strings.NewReader(content) },
+			computeContentLength: false,
+			wantChunked:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				chunked := len(r.TransferEncoding) != 0 && r.TransferEncoding[0] == "chunked"
+				if chunked != tt.wantChunked {
+					t.Errorf("invalid transfer encoding, got => %v", r.TransferEncoding)
+				}
+				if !tt.wantChunked && r.ContentLength <= 0 {
+					t.Errorf("content length must be set, got => %d", r.ContentLength)
+				}
+
+				file, header, err := r.FormFile("key")
+				if err != nil {
+					t.Errorf("failed to read from file  %s", err)
+					return
+				}
+				defer file.Close()
+
+				if header.Filename != "sample.csv" {
+					t.Errorf("invalid file name  got = %s", header.Filename)
+				}
+				buf := bytes.NewBuffer(nil)
+				if _, err = io.Copy(buf, file); err != nil {
+					t.Errorf("failed to copy multipart file = %s", err)
+				}
+				if diff := cmp.Diff(buf.String(), content); diff != "" {
+					t.Errorf("invalid postBody, diff = %s", diff)
+				}
+				_, _ = w.Write([]byte("success"))
+			}))
+			defer server.Close()
+
+			err := Post(server.URL).
+				Client(&http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}}).
+				MultipartAsFormFile("key", "sample.csv", tt.reader(), false).
+				StreamMultipart(tt.computeContentLength).
+				HandleBody(func(body []uint8) error {
+					if !reflect.DeepEqual(body, []byte("success")) {
+						t.Errorf("wrong response, got => %s", body)
+					}
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("failed to post %s", err)
+			}
+		})
+	}
+}
+
+func Test_client_multipartSize(t *testing.T) {
+	f, err := os.Open("testdata/sample.golden")
+	if err != nil {
+		t.Fatalf("cannot open file %q: %v", "testdata/sample.golden", err)
+	}
+	defer f.Close()
+
+	cli := &client{}
+	cli.MultipartAsFormFile("key", "sample.csv", bytes.NewReader([]byte("value")), true).
+		MultipartData("file", f, false)
+
+	boundary := "gorestboundary"
+	size, err := cli.multipartSize(boundary)
+	if err != nil {
+		t.Fatalf("multipartSize() error = %v", err)
+	}
+
+	body := bytes.NewBuffer(nil)
+	multipartWriter := mineMultipart.NewWriter(body)
+	if err = multipartWriter.SetBoundary(boundary); err != nil {
+		t.Fatalf("failed to set boundary %s", err)
+	}
+	if err = cli.writeMultipart(multipartWriter, false); err != nil {
+		t.Fatalf("writeMultipart() error = %v", err)
+	}
+	if size != int64(body.Len()) {
+		t.Errorf("multipartSize() = %d, want %d", size, body.Len())
+	}
+}
+
+func Test_client_Execute_multiple_multipart_parts_post(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("failed to read multipart %s", err)
+			return
+		}
+
+		type part struct {
+			FormName    string
+			FileName    string
+			ContentType string
+			Custom      string
+			Body        string
+		}
+		var got []part
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("failed to read part %s", err)
+				return
+			}
+			body, err := ioutil.ReadAll(p)
+			if err != nil {
+				t.Errorf("failed to read part body %s", err)
+				return
+			}
+			got = append(got, part{
+				FormName:    p.FormName(),
+				FileName:    p.FileName(),
+				ContentType: p.Header.Get("Content-Type"),
+				Custom:      p.Header.Get("X-Custom"),
+				Body:        string(body),
+			})
+		}
+
+		if diff := cmp.Diff(got, []part{
+			{FormName: "name", Body: "value"},
+			{FormName: "meta", ContentType: "application/json", Body: `{"key":"value"}`},
+			{FormName: "image", FileName: `a"b.png`, ContentType: "image/png", Body: "png"},
+			{FormName: "doc", FileName: "résumé.txt", ContentType: "application/octet-stream", Body: "text"},
+			{FormName: "custom", ContentType: "text/plain", Custom: "custom", Body: "custom part"},
+		}); diff != "" {
+			t.Errorf("invalid parts, diff = %s", diff)
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+
+	for _, streaming := range []bool{false, true} {
+		multipartRequest := Post(server.URL).
+			Client(&http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}).
+			MultipartData("name", strings.NewReader("value"), false).
+			MultipartWithContentType("meta", "", "application/json", strings.NewReader(`{"key":"value"}`)).
+			MultipartWithContentType("image", `a"b.png`, "image/png", strings.NewReader("png")).
+			MultipartAsFormFile("doc", "résumé.txt", strings.NewReader("text"), false).
+			MultipartPart(textproto.MIMEHeader{
+				"Content-Disposition": {`form-data; name="custom"`},
+				"Content-Type":        {"text/plain"},
+				"X-Custom":            {"custom"},
+			}, strings.NewReader("custom part"))
+		if streaming {
+			multipartRequest = multipartRequest.StreamMultipart(true)
+		}
+
+		err := multipartRequest.HandleBody(func(body []uint8) error {
+			if !reflect.DeepEqual(body, []byte("success")) {
+				t.Errorf("wrong response, got => %s", body)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to post %s", err)
+		}
+	}
+}
+
+func Test_formDataDisposition(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldName string
+		fileName  string
+		want      string
+	}{
+		{
+			name:      "field",
+			fieldName: "key",
+			want:      `form-data; name="key"`,
+		},
+		{
+			name:      "file",
+			fieldName: "key",
+			fileName:  "sample.csv",
+			want:      `form-data; name="key"; filename="sample.csv"`,
+		},
+		{
+			name:      "escaped",
+			fieldName: "a\"b",
+			fileName:  "c\"d\r\n.csv",
+			want:      `form-data; name="a%22b"; filename="c%22d%0D%0A.csv"; filename*=UTF-8''c%22d%0D%0A.csv`,
+		},
+		{
+			name:      "non_ascii",
+			fieldName: "key",
+			fileName:  "résumé 1.txt",
+			want:      `form-data; name="key"; filename="résumé 1.txt"; filename*=UTF-8''r%C3%A9sum%C3%A9%201.txt`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formDataDisposition(tt.fieldName, tt.fileName); got != tt.want {
+				t.Errorf("formDataDisposition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_HandleStream(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"))
+	}))
+	defer server.Close()
+
+	var ids []int
+	err := Get(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		HandleStream(func(r io.Reader) error {
+			decoder := json.NewDecoder(r)
+			for decoder.More() {
+				var row struct {
+					ID int `json:"id"`
+				}
+				if err := decoder.Decode(&row); err != nil {
+					return err
+				}
+				ids = append(ids, row.ID)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("failed to get %s", err)
+	}
+	if diff := cmp.Diff(ids, []int{1, 2, 3}); diff != "" {
+		t.Errorf("invalid rows, diff = %s", diff)
+	}
+}
+
+func Test_client_DownloadTo(t *testing.T) {
+	content := strings.Repeat("0123456789", 10000)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100000")
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	var lastReceived, lastTotal int64
+	buf := bytes.NewBuffer(nil)
+	err := Get(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		OnDownloadProgress(func(received, total int64) {
+			lastReceived, lastTotal = received, total
+		}).
+		DownloadTo(buf)
+	if err != nil {
+		t.Fatalf("failed to download %s", err)
+	}
+	if buf.String() != content {
+		t.Errorf("invalid content, got => %d bytes", buf.Len())
+	}
+	if lastReceived != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("invalid progress, received => %d, total => %d", lastReceived, lastTotal)
+	}
+}
+
+func Test_client_DownloadFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+		wantFile   bool
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			wantErr:    false,
+			wantFile:   true,
+		},
+		{
+			name:       "invalid_status_code",
+			statusCode: http.StatusNotFound,
+			wantErr:    true,
+			wantFile:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte("header1,header2\nvalue1,value2\n"))
+			}))
+			defer server.Close()
+
+			dir, err := ioutil.TempDir("", "gorest")
+			if err != nil {
+				t.Fatalf("failed to create temp dir %s", err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "sample.csv")
+
+			err = Get(server.URL).
+				Client(&http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}}).
+				DownloadFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read dir %s", err)
+			}
+			if !tt.wantFile {
+				if len(files) != 0 {
+					t.Errorf("file must not be created, got => %d files", len(files))
+				}
+				return
+			}
+			if len(files) != 1 {
+				t.Fatalf("temporary file must be removed, got => %d files", len(files))
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read file %s", err)
+			}
+			if diff := cmp.Diff(string(content), "header1,header2\nvalue1,value2\n"); diff != "" {
+				t.Errorf("invalid content, diff = %s", diff)
+			}
+		})
+	}
+}
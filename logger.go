@@ -0,0 +1,231 @@
+package gorest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultMaxLogBodySize is default max size of body written to log
+const defaultMaxLogBodySize = 4 * 1024
+
+// defaultRedactedHeaders are always redacted in log
+var defaultRedactedHeaders = []string{`Authorization`, `Proxy-Authorization`, `Cookie`, `Set-Cookie`, `X-Api-Key`}
+
+// Logger writes log of api call, *log.Logger implements it
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LogLevel decides what is written to log
+type LogLevel int
+
+const (
+	// LogBasic writes method, url, status and duration
+	LogBasic LogLevel = iota + 1
+	// LogHeaders writes headers in addition to LogBasic
+	LogHeaders
+	// LogBodies writes bodies in addition to LogHeaders
+	LogBodies
+)
+
+// LogOptions configures log of api call
+type LogOptions struct {
+	// Level is LogBasic if it is zero
+	Level LogLevel
+	// RedactHeaders are redacted in addition to Authorization, Cookie and X-Api-Key
+	RedactHeaders []string
+	// RedactJSONFields are redacted in json body like `password`
+	RedactJSONFields []string
+	// MaxBodySize truncates body, default is 4KiB
+	MaxBodySize int64
+}
+
+// requestLogger writes request and response to logger
+type requestLogger struct {
+	logger      Logger
+	level       LogLevel
+	headers     map[string]bool
+	redactBody  func(body []byte) []byte
+	maxBodySize int64
+}
+
+func newRequestLogger(logger Logger, options LogOptions) *requestLogger {
+	l := &requestLogger{
+		logger:      logger,
+		level:       options.Level,
+		headers:     map[string]bool{},
+		maxBodySize: options.MaxBodySize,
+	}
+	if l.level == 0 {
+		l.level = LogBasic
+	}
+	if l.maxBodySize <= 0 {
+		l.maxBodySize = defaultMaxLogBodySize
+	}
+	for _, header := range append(append([]string{}, defaultRedactedHeaders...), options.RedactHeaders...) {
+		l.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	if len(options.RedactJSONFields) != 0 {
+		l.redactBody = RedactJSONFields(options.RedactJSONFields...)
+	}
+	return l
+}
+
+// wrap writes log of each request sent by next
+func (l *requestLogger) wrap(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		var entry strings.Builder
+		if l.level >= LogHeaders {
+			l.writeHeaders(&entry, `> `, req.Header)
+		}
+		if l.level >= LogBodies {
+			l.writeRequestBody(&entry, req)
+		}
+
+		start := time.Now()
+		res, err := next.Do(req)
+		duration := time.Since(start)
+
+		if err != nil {
+			l.logger.Printf("%s %s error: %v (%s)\n%s", req.Method, req.URL.Redacted(), err, duration, entry.String())
+			return res, err
+		}
+		if l.level >= LogHeaders {
+			l.writeHeaders(&entry, `< `, res.Header)
+		}
+		if l.level >= LogBodies {
+			l.writeResponseBody(&entry, res)
+		}
+		l.logger.Printf("%s %s %s (%s)\n%s", req.Method, req.URL.Redacted(), res.Status, duration, entry.String())
+		return res, nil
+	})
+}
+
+func (l *requestLogger) writeHeaders(w *strings.Builder, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			if l.headers[http.CanonicalHeaderKey(key)] {
+				value = redactedValue
+			}
+			w.WriteString(prefix + key + `: ` + value + "\n")
+		}
+	}
+}
+
+func (l *requestLogger) writeRequestBody(w *strings.Builder, req *http.Request) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	if req.GetBody == nil {
+		// reading body like streaming multipart consumes it
+		w.WriteString("> (streaming body)\n")
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer func() { _ = body.Close() }()
+	data, truncated, err := readErrorBody(body, l.maxBodySize)
+	if err != nil {
+		return
+	}
+	w.WriteString(`> ` + l.formatBody(data, truncated) + "\n")
+}
+
+// writeResponseBody writes head of response body, and keeps it readable
+func (l *requestLogger) writeResponseBody(w *strings.Builder, res *http.Response) {
+	if res.Body == nil || res.Body == http.NoBody {
+		return
+	}
+	peeked, err := ioutil.ReadAll(io.LimitReader(res.Body, l.maxBodySize+1))
+	res.Body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(peeked), res.Body), body: res.Body}
+	if err != nil || len(peeked) == 0 {
+		return
+	}
+	if int64(len(peeked)) > l.maxBodySize {
+		w.WriteString(`< ` + l.formatBody(peeked[:l.maxBodySize], true) + "\n")
+		return
+	}
+	w.WriteString(`< ` + l.formatBody(peeked, false) + "\n")
+}
+
+// formatBody redacts json fields and truncates body
+func (l *requestLogger) formatBody(data []byte, truncated bool) string {
+	if l.redactBody != nil {
+		// truncated json is redacted by pattern
+		data = l.redactBody(data)
+	}
+	if truncated {
+		return string(data) + `...(truncated)`
+	}
+	return string(data)
+}
+
+// peekedBody is response body whose head is already read
+type peekedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (b *peekedBody) Close() error {
+	return b.body.Close()
+}
+
+// AsCurl builds request and returns it as curl command line.
+// credentials like Authorization header are not redacted.
+func (cli *client) AsCurl() (string, error) {
+	// body is read without reporting upload progress
+	req, err := cli.buildRequest(cli.context())
+	if err != nil {
+		return ``, err
+	}
+
+	args := []string{`curl`}
+	if req.Method == http.MethodHead {
+		args = append(args, `--head`)
+	} else if req.Method != http.MethodGet || req.Body != nil && req.Body != http.NoBody {
+		args = append(args, `-X`, req.Method)
+	}
+	args = append(args, shellQuote(req.URL.String()))
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			args = append(args, `-H`, shellQuote(key+`: `+value))
+		}
+	}
+
+	if _, ok := req.Body.(*multipartStream); ok {
+		// streaming body is never read, it is passed from stdin of curl
+		_ = req.Body.Close()
+		args = append(args, `--data-binary`, `@-`)
+	} else if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return ``, err
+		}
+		args = append(args, `--data-binary`, shellQuote(string(body)))
+	}
+	return strings.Join(args, ` `), nil
+}
+
+// shellQuote quotes s by single quotes for POSIX shell
+func shellQuote(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `'\''`) + `'`
+}
@@ -0,0 +1,209 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testLogger records log
+type testLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+// durationRegex removes duration from log
+var durationRegex = regexp.MustCompile(`\(\d[^)]*s\)`)
+
+func Test_client_Log(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Date", "Sun, 18 Oct 2026 00:00:00 GMT")
+		_, _ = w.Write([]byte(`{"access_token":"secret","name":"` + strings.Repeat("a", 20) + `"}`))
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	tests := []struct {
+		name    string
+		options LogOptions
+		want    string
+	}{
+		{
+			name: "basic",
+			want: "POST " + server.URL + "/login 200 OK ()\n",
+		},
+		{
+			name:    "headers",
+			options: LogOptions{Level: LogHeaders, RedactHeaders: []string{"X-Secret"}},
+			want: "POST " + server.URL + "/login 200 OK ()\n" +
+				"> Authorization: [REDACTED]\n" +
+				"> Content-Type: application/json\n" +
+				"> Cookie: [REDACTED]\n" +
+				"> X-Api-Key: [REDACTED]\n" +
+				"> X-Secret: [REDACTED]\n" +
+				"< Content-Length: 55\n" +
+				"< Content-Type: application/json\n" +
+				"< Date: Sun, 18 Oct 2026 00:00:00 GMT\n" +
+				"< Set-Cookie: [REDACTED]\n",
+		},
+		{
+			name:    "bodies",
+			options: LogOptions{Level: LogBodies, RedactHeaders: []string{"X-Secret"}, RedactJSONFields: []string{"password", "access_token"}},
+			want: "POST " + server.URL + "/login 200 OK ()\n" +
+				"> Authorization: [REDACTED]\n" +
+				"> Content-Type: application/json\n" +
+				"> Cookie: [REDACTED]\n" +
+				"> X-Api-Key: [REDACTED]\n" +
+				"> X-Secret: [REDACTED]\n" +
+				`> {"password":"[REDACTED]","user":"gorest"}` + "\n" +
+				"< Content-Length: 55\n" +
+				"< Content-Type: application/json\n" +
+				"< Date: Sun, 18 Oct 2026 00:00:00 GMT\n" +
+				"< Set-Cookie: [REDACTED]\n" +
+				`< {"access_token":"[REDACTED]","name":"aaaaaaaaaaaaaaaaaaaa"}` + "\n",
+		},
+		{
+			name:    "truncated_bodies",
+			options: LogOptions{Level: LogBodies, RedactJSONFields: []string{"password", "access_token"}, MaxBodySize: 30},
+			want: "POST " + server.URL + "/login 200 OK ()\n" +
+				`> {"password":"[REDACTED]","user":"g...(truncated)` + "\n" +
+				`< {"access_token":"[REDACTED]","name...(truncated)` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &testLogger{}
+			err := Post(server.URL).Path("/login").Client(httpClient).
+				Log(logger, tt.options).
+				BasicAuth("user", "pass").
+				Cookie(&http.Cookie{Name: "session", Value: "secret"}).
+				Header("X-Api-Key", "secret").
+				Header("X-Secret", "secret").
+				JSONString(`{"password":"secret","user":"gorest"}`).
+				HandleBody(func(body []uint8) error {
+					if !strings.HasSuffix(string(body), `"}`) {
+						t.Errorf("response body must be readable after logging, got => %s", body)
+					}
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("failed to post %s", err)
+			}
+			if len(logger.logs) != 1 {
+				t.Fatalf("request must be logged once, got => %v", logger.logs)
+			}
+			got := durationRegex.ReplaceAllString(logger.logs[0], "()")
+			if tt.name == "truncated_bodies" {
+				// only bodies are compared
+				got = strings.Join(removeHeaderLines(strings.Split(got, "\n")), "\n")
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("invalid log, diff = %s", diff)
+			}
+		})
+	}
+}
+
+func Test_client_Log_upload_progress(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+
+	var reported []int64
+	request := Post(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Log(&testLogger{}, LogOptions{Level: LogBodies}).
+		OnUploadProgress(func(sent, total int64) {
+			reported = append(reported, sent)
+		}).
+		JSONString(`{"a":1}`)
+
+	// reading body for curl or log is not reported
+	if _, err := request.AsCurl(); err != nil {
+		t.Fatalf("AsCurl() error = %v", err)
+	}
+	if err := request.HandleBody(func(body []uint8) error { return nil }); err != nil {
+		t.Fatalf("failed to post %s", err)
+	}
+	if diff := cmp.Diff(reported, []int64{7}); diff != "" {
+		t.Errorf("invalid progress, diff = %s", diff)
+	}
+}
+
+// removeHeaderLines removes lines of headers from log
+func removeHeaderLines(lines []string) []string {
+	var removed []string
+	for _, line := range lines {
+		if (strings.HasPrefix(line, "> ") || strings.HasPrefix(line, "< ")) && strings.Contains(line, ": ") &&
+			!strings.Contains(line, "{") {
+			continue
+		}
+		removed = append(removed, line)
+	}
+	return removed
+}
+
+func Test_client_AsCurl(t *testing.T) {
+	tests := []struct {
+		name    string
+		request Executor
+		want    string
+	}{
+		{
+			name: "get",
+			request: Get("https://sample.com").Path("/users").URLParam("q", "a b").
+				Header("Accept", "application/json"),
+			want: `curl 'https://sample.com/users?q=a+b' -H 'Accept: application/json'`,
+		},
+		{
+			name: "post_json",
+			request: Post("https://sample.com").Path("/users").BasicAuth("user", "pass").
+				JSONString(`{"name":"it's me"}`),
+			want: `curl -X POST 'https://sample.com/users' ` +
+				`-H 'Authorization: Basic dXNlcjpwYXNz' -H 'Content-Type: application/json' ` +
+				`--data-binary '{"name":"it'\''s me"}'`,
+		},
+		{
+			name:    "head",
+			request: Head("https://sample.com"),
+			want:    `curl --head 'https://sample.com'`,
+		},
+		{
+			name:    "url_encoded",
+			request: Put("https://sample.com").URLEncoded("key", "value"),
+			want: `curl -X PUT 'https://sample.com' ` +
+				`-H 'Content-Type: application/x-www-form-urlencoded' --data-binary 'key=value'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.request.AsCurl()
+			if err != nil {
+				t.Fatalf("AsCurl() error = %v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("AsCurl() diff = %s", diff)
+			}
+		})
+	}
+}
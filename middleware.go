@@ -0,0 +1,37 @@
+package gorest
+
+import "net/http"
+
+// Doer sends http request and returns response.
+// *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to use function as Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps Doer for adding behavior on every api call,
+// like logging, metrics, signing request or rewriting response.
+//
+//	logging := func(next gorest.Doer) gorest.Doer {
+//		return gorest.DoerFunc(func(req *http.Request) (*http.Response, error) {
+//			res, err := next.Do(req)
+//			log.Printf("%s %s", req.Method, req.URL)
+//			return res, err
+//		})
+//	}
+type Middleware func(next Doer) Doer
+
+// chainMiddlewares wraps doer by middlewares, the first middleware is outermost
+func chainMiddlewares(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
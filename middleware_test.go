@@ -0,0 +1,118 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func recordingMiddleware(name string, records *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*records = append(*records, "before "+name)
+			req.Header.Add("X-Middleware", name)
+			res, err := next.Do(req)
+			*records = append(*records, "after "+name)
+			return res, err
+		})
+	}
+}
+
+func Test_client_Use(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Join(r.Header.Values("X-Middleware"), ",")))
+	}))
+	defer server.Close()
+
+	var records []string
+	base := New(server.URL,
+		WithHTTPClient(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}),
+		WithMiddleware(recordingMiddleware("shared", &records)),
+	)
+
+	t.Run("execute", func(t *testing.T) {
+		records = nil
+		res, err := base.Get().
+			Use(recordingMiddleware("first", &records), recordingMiddleware("second", &records)).
+			Execute()
+		if err != nil {
+			t.Fatalf("failed to get %s", err)
+		}
+		defer CloseBody(res.Body)
+
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("failed to read response %s", err)
+		}
+		if diff := cmp.Diff(string(body), "shared,first,second"); diff != "" {
+			t.Errorf("invalid request headers, diff = %s", diff)
+		}
+		if diff := cmp.Diff(records, []string{
+			"before shared", "before first", "before second",
+			"after second", "after first", "after shared",
+		}); diff != "" {
+			t.Errorf("invalid order, diff = %s", diff)
+		}
+	})
+
+	t.Run("handle_body", func(t *testing.T) {
+		records = nil
+		err := base.Get().
+			Use(recordingMiddleware("first", &records)).
+			HandleBody(func(body []uint8) error {
+				if diff := cmp.Diff(string(body), "shared,first"); diff != "" {
+					t.Errorf("invalid request headers, diff = %s", diff)
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("failed to get %s", err)
+		}
+		if diff := cmp.Diff(records, []string{
+			"before shared", "before first", "after first", "after shared",
+		}); diff != "" {
+			t.Errorf("invalid order, diff = %s", diff)
+		}
+	})
+}
+
+func Test_client_Use_rewrite_response(t *testing.T) {
+	rewrite := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			res, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			CloseBody(res.Body)
+			res.Body = ioutil.NopCloser(strings.NewReader("rewritten"))
+			return res, nil
+		})
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("original"))
+	}))
+	defer server.Close()
+
+	err := Get(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Use(rewrite).
+		HandleBody(func(body []uint8) error {
+			if diff := cmp.Diff(string(body), "rewritten"); diff != "" {
+				t.Errorf("invalid response, diff = %s", diff)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("failed to get %s", err)
+	}
+}
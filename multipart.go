@@ -2,18 +2,26 @@ package gorest
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
 	mineMultipart "mime/multipart"
 	"net/textproto"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
 type multipartSetting struct {
 	key            string
 	fileName       string
+	contentType    string
+	header         textproto.MIMEHeader
 	forceMultipart bool
-	reader         io.Reader
+	source         *partSource
 }
 
 func (cli *client) MultipartData(key string, reader io.Reader, forceMultipart bool) Multipart {
@@ -24,82 +32,323 @@ func (cli *client) MultipartAsFormFile(key string, fileName string, reader io.Re
 	cli.multipartSettings = append(cli.multipartSettings, multipartSetting{
 		key:            key,
 		fileName:       fileName,
-		reader:         reader,
+		source:         newPartSource(reader),
 		forceMultipart: forceMultipart,
 	})
 	return cli
 }
 
-func (cli *client) setupMultipartRequest() (io.Reader, error) {
+func (cli *client) MultipartWithContentType(key string, fileName string, contentType string, reader io.Reader) Multipart {
+	cli.multipartSettings = append(cli.multipartSettings, multipartSetting{
+		key:         key,
+		fileName:    fileName,
+		contentType: contentType,
+		source:      newPartSource(reader),
+	})
+	return cli
+}
+
+func (cli *client) MultipartPart(header textproto.MIMEHeader, reader io.Reader) Multipart {
+	copied := make(textproto.MIMEHeader, len(header))
+	for key, values := range header {
+		copied[key] = append([]string{}, values...)
+	}
+	cli.multipartSettings = append(cli.multipartSettings, multipartSetting{
+		header: copied,
+		source: newPartSource(reader),
+	})
+	return cli
+}
+
+func (cli *client) StreamMultipart(computeContentLength bool) Multipart {
+	cli.multipartStreaming = true
+	cli.multipartContentLength = computeContentLength
+	return cli
+}
+
+// setupMultipartRequest returns buffered body and its content type which has boundary
+func (cli *client) setupMultipartRequest() (io.Reader, contentType, error) {
 	var body bytes.Buffer
 	multipartWriter := mineMultipart.NewWriter(&body)
-	var err error
+	if err := cli.writeMultipart(multipartWriter, false); err != nil {
+		return nil, notSet, err
+	}
+	return &body, contentType(multipartWriter.FormDataContentType()), nil
+}
 
-	for _, v := range cli.multipartSettings {
-		if err = func() error {
-			reader := v.reader
-			if x, ok := reader.(io.Closer); ok {
-				defer func() {
-					// ignore closing error
-					_ = x.Close()
-				}()
-			}
+// setupStreamingMultipartRequest returns body which writes parts into pipe while being read,
+// and its content type
+func (cli *client) setupStreamingMultipartRequest() (io.Reader, contentType, error) {
+	multipartWriter := mineMultipart.NewWriter(ioutil.Discard)
+	boundary := multipartWriter.Boundary()
 
-			var writer io.Writer
-			if file, ok := reader.(*os.File); ok {
-				if writer, err = cli.createFormFileAsMultipart(
-					v.key,
-					file.Name(),
-					v.forceMultipart,
-					multipartWriter,
-				); err != nil {
-					return err
-				}
-			} else if v.fileName != "" {
-				if writer, err = cli.createFormFileAsMultipart(
-					v.key,
-					v.fileName,
-					v.forceMultipart,
-					multipartWriter,
-				); err != nil {
-					return err
-				}
-			} else {
-				if writer, err = multipartWriter.CreateFormField(v.key); err != nil {
-					return err
-				}
-			}
+	size := int64(-1)
+	if cli.multipartContentLength {
+		var err error
+		if size, err = cli.multipartSize(boundary); err != nil {
+			return nil, notSet, err
+		}
+	}
 
-			if _, err = io.Copy(writer, reader); err != nil {
+	pipeReader, pipeWriter := io.Pipe()
+	return &multipartStream{
+		reader: pipeReader,
+		writer: pipeWriter,
+		size:   size,
+		write: func(w io.Writer) error {
+			multipartWriter := mineMultipart.NewWriter(w)
+			if err := multipartWriter.SetBoundary(boundary); err != nil {
 				return err
 			}
+			return cli.writeMultipart(multipartWriter, false)
+		},
+	}, contentType(multipartWriter.FormDataContentType()), nil
+}
 
-			if err = multipartWriter.Close(); err != nil {
-				return err
-			}
-			return nil
-		}(); err != nil {
-			return nil, err
+// multipartSize returns size of multipart body, or -1 if size of any reader is unknown
+func (cli *client) multipartSize(boundary string) (int64, error) {
+	var contentSize int64
+	for _, v := range cli.multipartSettings {
+		size, ok := v.source.size()
+		if !ok {
+			return -1, nil
 		}
+		contentSize += size
+	}
+
+	counter := &countWriter{}
+	multipartWriter := mineMultipart.NewWriter(counter)
+	if err := multipartWriter.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if err := cli.writeMultipart(multipartWriter, true); err != nil {
+		return 0, err
 	}
+	return counter.n + contentSize, nil
+}
 
-	cli.contentType = contentType(multipartWriter.FormDataContentType())
-	return &body, nil
+// writeMultipart writes all parts into multipartWriter, and closes it.
+// if headerOnly is true, contents of parts are not written.
+// readers of parts are never consumed or closed, so it can be called repeatedly.
+func (cli *client) writeMultipart(multipartWriter *mineMultipart.Writer, headerOnly bool) error {
+	for _, v := range cli.multipartSettings {
+		writer, err := multipartWriter.CreatePart(v.partHeader())
+		if err != nil {
+			return err
+		}
+		if headerOnly {
+			continue
+		}
+		reader, err := v.source.open(cli.multipartStreaming)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(writer, reader); err != nil {
+			return err
+		}
+	}
+	return multipartWriter.Close()
 }
 
-func (cli *client) createFormFileAsMultipart(
-	fieldName,
-	fileName string,
-	forceMultipart bool,
-	writer *mineMultipart.Writer,
-) (io.Writer, error) {
-	if !forceMultipart {
-		return writer.CreateFormFile(fieldName, fileName)
+// partHeader returns MIME header of the part
+func (v multipartSetting) partHeader() textproto.MIMEHeader {
+	if v.header != nil {
+		return v.header
+	}
+
+	fileName := v.fileName
+	if file, ok := v.source.reader.(*os.File); ok {
+		// RFC 7578, file name must not include directory
+		fileName = filepath.Base(file.Name())
 	}
 
 	header := make(textproto.MIMEHeader)
-	header.Set("Content-Disposition",
-		fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fieldName, fileName))
-	header.Set("Content-Type", string(multipart))
-	return writer.CreatePart(header)
+	header.Set("Content-Disposition", formDataDisposition(v.key, fileName))
+	switch {
+	case v.contentType != "":
+		header.Set("Content-Type", v.contentType)
+	case fileName == "":
+	case v.forceMultipart:
+		header.Set("Content-Type", string(multipart))
+	default:
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	return header
+}
+
+// formDataDisposition returns Content-Disposition of form-data part.
+// name and file name are escaped by RFC 7578,
+// escaped or non ASCII file name is also set as filename* by RFC 5987.
+func formDataDisposition(fieldName, fileName string) string {
+	disposition := fmt.Sprintf(`form-data; name="%s"`, escapeFormDataParam(fieldName))
+	if fileName == "" {
+		return disposition
+	}
+	escaped := escapeFormDataParam(fileName)
+	disposition = fmt.Sprintf(`%s; filename="%s"`, disposition, escaped)
+	if escaped != fileName || !isASCII(fileName) {
+		disposition = fmt.Sprintf(`%s; filename*=UTF-8''%s`, disposition, encodeExtValue(fileName))
+	}
+	return disposition
+}
+
+var formDataParamEscaper = strings.NewReplacer("\"", "%22", "\r", "%0D", "\n", "%0A")
+
+// escapeFormDataParam escapes quoted parameter of Content-Disposition like browsers
+func escapeFormDataParam(s string) string {
+	return formDataParamEscaper.Replace(s)
+}
+
+// encodeExtValue encodes value by RFC 5987 ext-value (percent-encoded UTF-8)
+func encodeExtValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// isAttrChar reports whether c is attr-char of RFC 5987
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) != -1
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// multipartStream is request body which starts writing parts at first Read
+type multipartStream struct {
+	once   sync.Once
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	write  func(w io.Writer) error
+	// size is -1 if unknown
+	size int64
+}
+
+func (s *multipartStream) Read(p []byte) (int, error) {
+	s.once.Do(func() {
+		go func() {
+			_ = s.writer.CloseWithError(s.write(s.writer))
+		}()
+	})
+	return s.reader.Read(p)
+}
+
+// Close stops writing parts
+func (s *multipartStream) Close() error {
+	return s.reader.Close()
+}
+
+// partSource opens content of part repeatedly without consuming reader of caller.
+// io.ReaderAt and io.Seeker like *os.File are read from the offset when the part is added,
+// other readers are buffered at first read, or read only once by StreamMultipart.
+type partSource struct {
+	reader   io.Reader
+	readerAt io.ReaderAt
+	offset   int64
+
+	mu       sync.Mutex
+	buffered []byte
+	loaded   bool
+	consumed bool
+}
+
+func newPartSource(reader io.Reader) *partSource {
+	source := &partSource{reader: reader}
+	readerAt, ok := reader.(io.ReaderAt)
+	if !ok {
+		return source
+	}
+	if seeker, ok := reader.(io.Seeker); ok {
+		// non regular file like pipe cannot seek
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			source.readerAt, source.offset = readerAt, offset
+		}
+	}
+	return source
+}
+
+// open returns new reader of the content, it is safe for concurrent use.
+// if stream is true, reader which cannot be read again is returned without buffering.
+func (s *partSource) open(stream bool) (io.Reader, error) {
+	if buffer, ok := s.reader.(*bytes.Buffer); ok {
+		// bytes of buffer are read without draining it
+		return bytes.NewReader(buffer.Bytes()), nil
+	}
+	if s.readerAt != nil {
+		return io.NewSectionReader(s.readerAt, s.offset, math.MaxInt64-s.offset), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return bytes.NewReader(s.buffered), nil
+	}
+	if s.consumed {
+		return nil, errors.New(`multipart reader is already read, use io.ReaderAt and io.Seeker like *os.File to send it again`)
+	}
+	s.consumed = true
+	if stream {
+		return s.reader, nil
+	}
+	buffered, err := ioutil.ReadAll(s.reader)
+	if err != nil {
+		return nil, err
+	}
+	s.buffered, s.loaded = buffered, true
+	return bytes.NewReader(s.buffered), nil
+}
+
+// size returns size of the content if it is known
+func (s *partSource) size() (int64, bool) {
+	switch r := s.reader.(type) {
+	case *bytes.Buffer:
+		return int64(r.Len()), true
+	case *bytes.Reader:
+		return r.Size() - s.offset, true
+	case *strings.Reader:
+		return r.Size() - s.offset, true
+	case *os.File:
+		if s.readerAt == nil {
+			return 0, false
+		}
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		return info.Size() - s.offset, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return int64(len(s.buffered)), true
+	}
+	return 0, false
+}
+
+// countWriter counts written bytes
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
 }
@@ -0,0 +1,224 @@
+package gorest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExpiryDelta is time before expiry to refresh token
+	defaultExpiryDelta = 10 * time.Second
+	// defaultFetchTimeout limits request to token endpoint
+	defaultFetchTimeout = 30 * time.Second
+)
+
+// OAuth2Config is settings of OAuth2 token endpoint
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// EndpointParams are additional parameters for token endpoint
+	EndpointParams url.Values
+	// AuthInParams sends client id and secret as form parameters instead of basic auth
+	AuthInParams bool
+	// HTTPClient is used for token endpoint, default is http.DefaultClient
+	HTTPClient *http.Client
+	// ExpiryDelta is time before expiry to refresh token, default is 10s
+	ExpiryDelta time.Duration
+	// FetchTimeout limits request to token endpoint, default is 30s.
+	// the request is shared by concurrent callers, so it is not canceled by context of a caller.
+	FetchTimeout time.Duration
+}
+
+// OAuth2Error is error response of token endpoint
+type OAuth2Error struct {
+	ErrorCode        string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ErrorURI         string `json:"error_uri"`
+}
+
+func (e *OAuth2Error) Error() string {
+	if e.ErrorDescription == `` {
+		return fmt.Sprintf(`oauth2: %s`, e.ErrorCode)
+	}
+	return fmt.Sprintf(`oauth2: %s, %s`, e.ErrorCode, e.ErrorDescription)
+}
+
+// ClientCredentialsTokenSource returns TokenSource of client credentials grant.
+// token is cached and refreshed before expiry, concurrent refreshes are merged into one.
+func ClientCredentialsTokenSource(config OAuth2Config) TokenSource {
+	return newCachedTokenSource(config, nil, func(ctx context.Context, _ *Token) (*Token, error) {
+		params := url.Values{`grant_type`: {`client_credentials`}}
+		if len(config.Scopes) != 0 {
+			params.Set(`scope`, strings.Join(config.Scopes, ` `))
+		}
+		return config.requestToken(ctx, params)
+	})
+}
+
+// RefreshTokenSource returns TokenSource of refresh token grant.
+// token is cached and refreshed before expiry, concurrent refreshes are merged into one.
+// new refresh token is used if token endpoint returns it.
+func RefreshTokenSource(config OAuth2Config, refreshToken string) TokenSource {
+	initial := &Token{RefreshToken: refreshToken}
+	return newCachedTokenSource(config, initial, func(ctx context.Context, current *Token) (*Token, error) {
+		if current == nil || current.RefreshToken == `` {
+			return nil, errors.New(`oauth2: refresh token is not set`)
+		}
+		params := url.Values{
+			`grant_type`:    {`refresh_token`},
+			`refresh_token`: {current.RefreshToken},
+		}
+		token, err := config.requestToken(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if token.RefreshToken == `` {
+			token.RefreshToken = current.RefreshToken
+		}
+		return token, nil
+	})
+}
+
+// requestToken requests token to token endpoint
+func (config OAuth2Config) requestToken(ctx context.Context, params url.Values) (*Token, error) {
+	for key, values := range config.EndpointParams {
+		params[key] = append(params[key], values...)
+	}
+
+	request := Post(config.TokenURL).
+		Context(ctx).
+		Header(`Accept`, `application/json`).
+		OnError(&OAuth2Error{})
+	if config.HTTPClient != nil {
+		request = request.Client(config.HTTPClient)
+	}
+	if config.AuthInParams {
+		params.Set(`client_id`, config.ClientID)
+		if config.ClientSecret != `` {
+			params.Set(`client_secret`, config.ClientSecret)
+		}
+	} else {
+		request = request.BasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := request.URLEncodedEscapedString(params.Encode()).DecodeJSON(&body); err != nil {
+		return nil, err
+	}
+	if body.AccessToken == `` {
+		return nil, errors.New(`oauth2: token endpoint returned no access token`)
+	}
+
+	token := &Token{
+		AccessToken:  body.AccessToken,
+		TokenType:    body.TokenType,
+		RefreshToken: body.RefreshToken,
+	}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// cachedTokenSource caches token, and fetches new token if it is expired
+type cachedTokenSource struct {
+	mu           sync.Mutex
+	token        *Token
+	fetching     *tokenFetch
+	fetch        func(ctx context.Context, current *Token) (*Token, error)
+	expiryDelta  time.Duration
+	fetchTimeout time.Duration
+	now          func() time.Time
+}
+
+// tokenFetch is fetching token shared by concurrent callers
+type tokenFetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+func newCachedTokenSource(
+	config OAuth2Config,
+	initial *Token,
+	fetch func(ctx context.Context, current *Token) (*Token, error),
+) *cachedTokenSource {
+	expiryDelta := config.ExpiryDelta
+	if expiryDelta <= 0 {
+		expiryDelta = defaultExpiryDelta
+	}
+	fetchTimeout := config.FetchTimeout
+	if fetchTimeout <= 0 {
+		fetchTimeout = defaultFetchTimeout
+	}
+	return &cachedTokenSource{
+		token:        initial,
+		fetch:        fetch,
+		expiryDelta:  expiryDelta,
+		fetchTimeout: fetchTimeout,
+		now:          time.Now,
+	}
+}
+
+func (s *cachedTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	if s.token != nil && s.token.AccessToken != `` && !s.token.expired(s.now(), s.expiryDelta) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+
+	fetching := s.fetching
+	if fetching == nil {
+		fetching = &tokenFetch{done: make(chan struct{})}
+		s.fetching = fetching
+		go s.refresh(fetching, s.token)
+	}
+	s.mu.Unlock()
+
+	// each caller waits only by its own context
+	select {
+	case <-fetching.done:
+		return fetching.token, fetching.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh fetches token by context detached from callers, and shares result by fetching
+func (s *cachedTokenSource) refresh(fetching *tokenFetch, current *Token) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.fetchTimeout)
+	defer cancel()
+	fetching.token, fetching.err = s.fetch(ctx, current)
+
+	s.mu.Lock()
+	if fetching.err == nil {
+		s.token = fetching.token
+	}
+	s.fetching = nil
+	s.mu.Unlock()
+	close(fetching.done)
+}
+
+// Invalidate discards cached token if it is the access token
+func (s *cachedTokenSource) Invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && s.token.AccessToken == accessToken {
+		// keep refresh token
+		s.token = &Token{RefreshToken: s.token.RefreshToken}
+	}
+}
@@ -0,0 +1,226 @@
+package gorest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTokenServer returns token endpoint which issues `token-N`
+func newTokenServer(t *testing.T, expiresIn int, delay time.Duration, issued *int32) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form %s", err)
+		}
+		if id, secret, ok := r.BasicAuth(); ok && (id != "client" || secret != "secret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"unknown client"}`))
+			return
+		}
+		if r.PostForm.Get("grant_type") == "refresh_token" && r.PostForm.Get("refresh_token") == "" {
+			t.Error("refresh token must be sent")
+		}
+		time.Sleep(delay)
+
+		n := atomic.AddInt32(issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d,"refresh_token":"refresh-%d"}`,
+			n, expiresIn, n)
+	}))
+}
+
+func testOAuth2Config(tokenURL string) OAuth2Config {
+	return OAuth2Config{
+		TokenURL:     tokenURL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+		HTTPClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}},
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, 0, &issued)
+	defer tokenServer.Close()
+
+	source := ClientCredentialsTokenSource(testOAuth2Config(tokenServer.URL))
+	for i := 0; i < 3; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if diff := cmp.Diff(token.AccessToken, "token-1"); diff != "" {
+			t.Errorf("token must be cached, diff = %s", diff)
+		}
+		if token.Type() != "Bearer" {
+			t.Errorf("invalid token type, got => %s", token.Type())
+		}
+	}
+	if got := atomic.LoadInt32(&issued); got != 1 {
+		t.Errorf("token must be fetched once, got => %d", got)
+	}
+}
+
+func TestClientCredentialsTokenSource_refresh_before_expiry(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 5, 0, &issued)
+	defer tokenServer.Close()
+
+	// expires in 5s, but refreshed 10s before expiry
+	source := ClientCredentialsTokenSource(testOAuth2Config(tokenServer.URL))
+	for i := 1; i <= 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if diff := cmp.Diff(token.AccessToken, fmt.Sprintf("token-%d", i)); diff != "" {
+			t.Errorf("token must be refreshed, diff = %s", diff)
+		}
+	}
+}
+
+func TestClientCredentialsTokenSource_concurrent(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, 50*time.Millisecond, &issued)
+	defer tokenServer.Close()
+
+	source := ClientCredentialsTokenSource(testOAuth2Config(tokenServer.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := source.Token(context.Background())
+			if err != nil {
+				t.Errorf("Token() error = %v", err)
+				return
+			}
+			if token.AccessToken != "token-1" {
+				t.Errorf("invalid token, got => %s", token.AccessToken)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&issued); got != 1 {
+		t.Errorf("concurrent refreshes must be merged, got => %d", got)
+	}
+}
+
+func TestClientCredentialsTokenSource_canceled_caller(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, 100*time.Millisecond, &issued)
+	defer tokenServer.Close()
+
+	source := ClientCredentialsTokenSource(testOAuth2Config(tokenServer.URL))
+
+	// first caller starts fetching, and gives up before response
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := source.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Token() must be stopped by context, got => %v", err)
+	}
+
+	// fetching is not canceled by the first caller
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "token-1" {
+		t.Errorf("invalid token, got => %s", token.AccessToken)
+	}
+	if got := atomic.LoadInt32(&issued); got != 1 {
+		t.Errorf("fetching must be shared, got => %d", got)
+	}
+}
+
+func TestClientCredentialsTokenSource_error(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, 0, &issued)
+	defer tokenServer.Close()
+
+	config := testOAuth2Config(tokenServer.URL)
+	config.ClientSecret = "invalid"
+	_, err := ClientCredentialsTokenSource(config).Token(context.Background())
+
+	var oauth2Err *OAuth2Error
+	if !errors.As(err, &oauth2Err) {
+		t.Fatalf("error must be OAuth2Error, got => %v", err)
+	}
+	if diff := cmp.Diff(oauth2Err, &OAuth2Error{ErrorCode: "invalid_client", ErrorDescription: "unknown client"}); diff != "" {
+		t.Errorf("invalid error, diff = %s", diff)
+	}
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, 0, &issued)
+	defer tokenServer.Close()
+
+	source := RefreshTokenSource(testOAuth2Config(tokenServer.URL), "initial")
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if diff := cmp.Diff(token.RefreshToken, "refresh-1"); diff != "" {
+		t.Errorf("refresh token must be rotated, diff = %s", diff)
+	}
+
+	source.(TokenInvalidator).Invalidate(token.AccessToken)
+	token, err = source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if diff := cmp.Diff(token.AccessToken, "token-2"); diff != "" {
+		t.Errorf("token must be refreshed after invalidation, diff = %s", diff)
+	}
+}
+
+func TestClientCredentialsTokenSource_api(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, 0, &issued)
+	defer tokenServer.Close()
+
+	var requests int32
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// first token is revoked
+		if atomic.AddInt32(&requests, 1) == 1 || r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer apiServer.Close()
+
+	api := New(apiServer.URL,
+		WithHTTPClient(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}),
+		WithTokenSource(ClientCredentialsTokenSource(testOAuth2Config(tokenServer.URL))),
+	)
+	err := api.Get().HandleBody(func(body []uint8) error {
+		if string(body) != "success" {
+			t.Errorf("wrong response, got => %s", body)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to get %s", err)
+	}
+	if got := atomic.LoadInt32(&issued); got != 2 {
+		t.Errorf("token must be fetched again after 401, got => %d", got)
+	}
+}
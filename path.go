@@ -0,0 +1,66 @@
+package gorest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pathParamPattern matches placeholder like `{owner}`
+var pathParamPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// formattedPlaceholderPattern matches placeholder of path format kept through fmt.Sprintf
+var formattedPlaceholderPattern = regexp.MustCompile("\x00([A-Za-z_][A-Za-z0-9_]*)\x00")
+
+// formatPath formats path by args. placeholders are expanded only in pathFmt,
+// so braces in args are escaped, like `{draft}` in Path("/items/%s", "{draft}").
+func formatPath(pathFmt string, args ...interface{}) string {
+	if len(args) == 0 {
+		return fmt.Sprintf(pathFmt, args...)
+	}
+	marked := pathParamPattern.ReplaceAllString(pathFmt, "\x00${1}\x00")
+	formatted := strings.NewReplacer(`{`, `%7B`, `}`, `%7D`).Replace(fmt.Sprintf(marked, args...))
+	return formattedPlaceholderPattern.ReplaceAllString(formatted, `{${1}}`)
+}
+
+// expandPath replaces placeholders in path with escaped path parameters
+func expandPath(path string, params map[string]string) (string, error) {
+	var missing []string
+	expanded := pathParamPattern.ReplaceAllStringFunc(path, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return url.PathEscape(value)
+	})
+	if len(missing) != 0 {
+		return ``, fmt.Errorf(`path parameter is not set, %s in %q`, strings.Join(missing, `, `), path)
+	}
+	return expanded, nil
+}
+
+// joinPaths joins base url and paths with single slash.
+// query and fragment of base url are kept after the paths.
+func joinPaths(baseURL string, paths []string) string {
+	endpoint, suffix := baseURL, ``
+	if i := strings.IndexAny(baseURL, `?#`); i != -1 {
+		endpoint, suffix = baseURL[:i], baseURL[i:]
+	}
+
+	for _, path := range paths {
+		switch {
+		case path == ``:
+			continue
+		case strings.HasPrefix(path, `?`), strings.HasPrefix(path, `#`):
+			endpoint = concat(endpoint, path)
+		case endpoint == ``:
+			endpoint = path
+		default:
+			endpoint = concat(strings.TrimRight(endpoint, `/`), concat(`/`, strings.TrimLeft(path, `/`)))
+		}
+	}
+	return concat(endpoint, suffix)
+}
@@ -0,0 +1,148 @@
+package gorest
+
+import "testing"
+
+func Test_expandPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		params  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no_placeholder",
+			path:   "/users",
+			params: nil,
+			want:   "/users",
+		},
+		{
+			name:   "placeholders",
+			path:   "/repos/{owner}/{repo}",
+			params: map[string]string{"owner": "izumix03", "repo": "gorest"},
+			want:   "/repos/izumix03/gorest",
+		},
+		{
+			name:   "escaped",
+			path:   "/files/{id}",
+			params: map[string]string{"id": "a/b?c d"},
+			want:   "/files/a%2Fb%3Fc%20d",
+		},
+		{
+			name:    "missing",
+			path:    "/repos/{owner}/{repo}",
+			params:  map[string]string{"owner": "izumix03"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPath(tt.path, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expandPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		pathFmt string
+		args    []interface{}
+		want    string
+	}{
+		{
+			name:    "no_args",
+			pathFmt: "/repos/{owner}",
+			want:    "/repos/{owner}",
+		},
+		{
+			name:    "formatted",
+			pathFmt: "/users/%s/blog/%d",
+			args:    []interface{}{"takahiro", 1},
+			want:    "/users/takahiro/blog/1",
+		},
+		{
+			name:    "braces_in_args",
+			pathFmt: "/items/%s",
+			args:    []interface{}{"{draft}"},
+			want:    "/items/%7Bdraft%7D",
+		},
+		{
+			name:    "placeholder_and_args",
+			pathFmt: "/repos/{owner}/%s/%v",
+			args:    []interface{}{"{repo}", struct{ ID int }{1}},
+			want:    "/repos/{owner}/%7Brepo%7D/%7B1%7D",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPath(tt.pathFmt, tt.args...); got != tt.want {
+				t.Errorf("formatPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_joinPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		paths   []string
+		want    string
+	}{
+		{
+			name:    "no_path",
+			baseURL: "https://sample.com/",
+			paths:   nil,
+			want:    "https://sample.com/",
+		},
+		{
+			name:    "simple",
+			baseURL: "https://sample.com",
+			paths:   []string{"/users", "/blog/1"},
+			want:    "https://sample.com/users/blog/1",
+		},
+		{
+			name:    "double_slash",
+			baseURL: "https://sample.com/api/",
+			paths:   []string{"/users/", "//blog"},
+			want:    "https://sample.com/api/users/blog",
+		},
+		{
+			name:    "missing_slash",
+			baseURL: "https://sample.com/api",
+			paths:   []string{"users", "blog"},
+			want:    "https://sample.com/api/users/blog",
+		},
+		{
+			name:    "trailing_slash_kept",
+			baseURL: "https://sample.com",
+			paths:   []string{"users/"},
+			want:    "https://sample.com/users/",
+		},
+		{
+			name:    "base_url_query",
+			baseURL: "https://sample.com/api?key=value",
+			paths:   []string{"/users"},
+			want:    "https://sample.com/api/users?key=value",
+		},
+		{
+			name:    "query_path",
+			baseURL: "https://sample.com",
+			paths:   []string{"/users", "?key=value"},
+			want:    "https://sample.com/users?key=value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinPaths(tt.baseURL, tt.paths); got != tt.want {
+				t.Errorf("joinPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package gorest
+
+import (
+	"io"
+	"net/http"
+)
+
+// ProgressFunc receives transferred bytes and total bytes,
+// total is -1 if length is unknown.
+type ProgressFunc func(transferred, total int64)
+
+// progressReader reports progress of reading
+type progressReader struct {
+	io.ReadCloser
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func newProgressReader(body io.ReadCloser, total int64, progress ProgressFunc) *progressReader {
+	return &progressReader{
+		ReadCloser: body,
+		total:      total,
+		progress:   progress,
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.transferred += int64(n)
+		r.progress(r.transferred, r.total)
+	}
+	return n, err
+}
+
+// withUploadProgress returns copy of req whose body reports progress,
+// body and GetBody of req are kept for retry.
+func withUploadProgress(req *http.Request, progress ProgressFunc) *http.Request {
+	watched := *req
+	watchUploadProgress(&watched, progress)
+	return &watched
+}
+
+// watchUploadProgress wraps request body for reporting progress,
+// body from GetBody for retry is also wrapped.
+func watchUploadProgress(req *http.Request, progress ProgressFunc) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	req.Body = newProgressReader(req.Body, total, progress)
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return newProgressReader(body, total, progress), nil
+		}
+	}
+}
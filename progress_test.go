@@ -0,0 +1,89 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_client_OnUploadProgress(t *testing.T) {
+	content := strings.Repeat("0123456789", 10000)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(ioutil.Discard, r.Body); err != nil {
+			t.Errorf("failed to read body %s", err)
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		operator  func(TerminalOperator) Executor
+		wantTotal bool
+	}{
+		{
+			name: "json",
+			operator: func(operator TerminalOperator) Executor {
+				return operator.JSONString(`"` + content + `"`)
+			},
+			wantTotal: true,
+		},
+		{
+			name: "multipart",
+			operator: func(operator TerminalOperator) Executor {
+				return operator.MultipartAsFormFile("file", "sample.txt", strings.NewReader(content), false)
+			},
+			wantTotal: true,
+		},
+		{
+			name: "streaming_multipart_unknown_size",
+			operator: func(operator TerminalOperator) Executor {
+				return operator.
+					MultipartAsFormFile("file", "sample.txt", io.MultiReader(strings.NewReader(content)), false).
+					StreamMultipart(true)
+			},
+			wantTotal: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			var lastSent, lastTotal int64
+			operator := Post(server.URL).
+				Client(&http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}}).
+				OnUploadProgress(func(sent, total int64) {
+					calls++
+					if sent < lastSent {
+						t.Errorf("sent must increase, got => %d after %d", sent, lastSent)
+					}
+					lastSent, lastTotal = sent, total
+				})
+
+			res, err := tt.operator(operator).Execute()
+			if err != nil {
+				t.Fatalf("failed to post %s", err)
+			}
+			CloseBody(res.Body)
+
+			if calls == 0 {
+				t.Fatal("progress is never reported")
+			}
+			if lastSent < int64(len(content)) {
+				t.Errorf("sent must include whole content, got => %d", lastSent)
+			}
+			if tt.wantTotal && lastTotal != lastSent {
+				t.Errorf("total must be sent bytes, got => %d, sent => %d", lastTotal, lastSent)
+			}
+			if !tt.wantTotal && lastTotal != -1 {
+				t.Errorf("total must be unknown, got => %d", lastTotal)
+			}
+		})
+	}
+}
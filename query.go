@@ -0,0 +1,162 @@
+package gorest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodeQueryStruct encodes struct fields into url.Values by `url` tag.
+//
+//	type Search struct {
+//		Query string   `url:"q"`
+//		Tags  []string `url:"tag,omitempty"`
+//		Page  int      `url:"page,omitempty"`
+//		Debug bool     `url:"-"`
+//	}
+//
+// field without tag uses field name as key, slice adds same key repeatedly,
+// time.Time is formatted by RFC3339.
+func encodeQueryStruct(v interface{}) (url.Values, error) {
+	values := url.Values{}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New(`query struct requires struct`)
+	}
+	if err := encodeQueryFields(values, rv); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeQueryFields(values url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get(`url`)
+		if tag == `-` {
+			continue
+		}
+		name, opts := parseQueryTag(tag)
+
+		fv := rv.Field(i)
+		if field.Anonymous && name == `` {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				if err := encodeQueryFields(values, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != `` {
+			// unexported
+			continue
+		}
+		if name == `` {
+			name = field.Name
+		}
+		if strings.Contains(opts, `omitempty`) && isEmptyValue(fv) {
+			continue
+		}
+
+		if err := addQueryValue(values, name, fv); err != nil {
+			return fmt.Errorf(`invalid query field %s, %s`, field.Name, err)
+		}
+	}
+	return nil
+}
+
+func parseQueryTag(tag string) (string, string) {
+	if i := strings.Index(tag, `,`); i != -1 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ``
+}
+
+func addQueryValue(values url.Values, name string, fv reflect.Value) error {
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	if fv.CanInterface() {
+		if t, ok := fv.Interface().(time.Time); ok {
+			values.Add(name, t.Format(time.RFC3339))
+			return nil
+		}
+		if stringer, ok := fv.Interface().(fmt.Stringer); ok {
+			values.Add(name, stringer.String())
+			return nil
+		}
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8 {
+			values.Add(name, string(fv.Bytes()))
+			return nil
+		}
+		for i := 0; i < fv.Len(); i++ {
+			if err := addQueryValue(values, name, fv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		values.Add(name, fv.String())
+	case reflect.Bool:
+		values.Add(name, strconv.FormatBool(fv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(name, strconv.FormatInt(fv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(name, strconv.FormatUint(fv.Uint(), 10))
+	case reflect.Float32:
+		values.Add(name, strconv.FormatFloat(fv.Float(), 'f', -1, 32))
+	case reflect.Float64:
+		values.Add(name, strconv.FormatFloat(fv.Float(), 'f', -1, 64))
+	default:
+		return fmt.Errorf(`unsupported type %s`, fv.Type())
+	}
+	return nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType && v.CanInterface() {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package gorest
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type queryPage struct {
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+}
+
+func Test_encodeQueryStruct(t *testing.T) {
+	name := "name"
+	since := time.Date(2020, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    url.Values
+		wantErr bool
+	}{
+		{
+			name: "tags",
+			v: struct {
+				Query   string  `url:"q"`
+				Name    *string `url:"name"`
+				Limit   uint    `url:"limit"`
+				Score   float64 `url:"score"`
+				Active  bool    `url:"active"`
+				Ignored string  `url:"-"`
+				NoTag   string
+				private string
+			}{
+				Query:   "a&b",
+				Name:    &name,
+				Limit:   10,
+				Score:   1.5,
+				Active:  true,
+				Ignored: "ignored",
+				NoTag:   "value",
+				private: "private",
+			},
+			want: url.Values{
+				"q":      []string{"a&b"},
+				"name":   []string{"name"},
+				"limit":  []string{"10"},
+				"score":  []string{"1.5"},
+				"active": []string{"true"},
+				"NoTag":  []string{"value"},
+			},
+		},
+		{
+			name: "omitempty",
+			v: &struct {
+				Query string    `url:"q,omitempty"`
+				Name  *string   `url:"name,omitempty"`
+				Since time.Time `url:"since,omitempty"`
+				Tags  []string  `url:"tag,omitempty"`
+				Count int       `url:"count"`
+			}{},
+			want: url.Values{
+				"count": []string{"0"},
+			},
+		},
+		{
+			name: "slice_and_time",
+			v: struct {
+				IDs   []int     `url:"id"`
+				Since time.Time `url:"since"`
+			}{
+				IDs:   []int{1, 2},
+				Since: since,
+			},
+			want: url.Values{
+				"id":    []string{"1", "2"},
+				"since": []string{"2020-10-05T12:00:00Z"},
+			},
+		},
+		{
+			name: "embedded",
+			v: struct {
+				queryPage
+				Query string `url:"q"`
+			}{
+				queryPage: queryPage{Page: 2},
+				Query:     "go",
+			},
+			want: url.Values{
+				"page": []string{"2"},
+				"q":    []string{"go"},
+			},
+		},
+		{
+			name:    "not_struct",
+			v:       map[string]string{"key": "value"},
+			wantErr: true,
+		},
+		{
+			name: "unsupported_field",
+			v: struct {
+				Nested map[string]string `url:"nested"`
+			}{
+				Nested: map[string]string{"key": "value"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeQueryStruct(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encodeQueryStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("encodeQueryStruct() diff = %s", diff)
+			}
+		})
+	}
+}
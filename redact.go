@@ -0,0 +1,60 @@
+package gorest
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces sensitive values
+const redactedValue = `[REDACTED]`
+
+// RedactJSONFields returns function which replaces values of the fields in json body,
+// fields are matched in any depth and case insensitive.
+// body which cannot be parsed like truncated json is redacted by pattern of `"field": value`,
+// so body which is not json is returned as it is.
+func RedactJSONFields(fields ...string) func(body []byte) []byte {
+	if len(fields) == 0 {
+		return func(body []byte) []byte { return body }
+	}
+	names := make(map[string]bool, len(fields))
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names[strings.ToLower(field)] = true
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	fieldsRegex := regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, `|`) + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+
+	return func(body []byte) []byte {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return fieldsRegex.ReplaceAll(body, []byte(`${1}"`+redactedValue+`"`))
+		}
+		redacted, err := json.Marshal(redactJSONValue(value, names))
+		if err != nil {
+			return body
+		}
+		return redacted
+	}
+}
+
+func redactJSONValue(value interface{}, names map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if names[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactJSONValue(child, names)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactJSONValue(child, names)
+		}
+	}
+	return value
+}
@@ -0,0 +1,219 @@
+package gorest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 4
+	defaultRetryBaseBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff  = 10 * time.Second
+)
+
+// defaultRetryStatusCodes are used if RetryPolicy.StatusCodes is nil
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures retry of api call.
+// zero value fields are replaced with default values.
+type RetryPolicy struct {
+	// MaxAttempts is number of attempts including the first one,
+	// default is 4, and 1 means never retry.
+	MaxAttempts int
+	// BaseBackoff is wait time before first retry, doubled by every retry.
+	// default is 100ms.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait time. default is 10s.
+	// if Retry-After header is longer than it, retry stops and the response is returned.
+	MaxBackoff time.Duration
+	// Jitter is ratio of random reduction of wait time, between 0 and 1.
+	Jitter float64
+	// StatusCodes are retryable status codes.
+	// default is 429, 502, 503 and 504.
+	StatusCodes []int
+	// RetryOnError decides whether error from transport is retryable.
+	// default is DefaultRetryOnError.
+	RetryOnError func(err error) bool
+	// RetryNonIdempotent allows retry of non idempotent method like POST, PATCH.
+	// request which has Idempotency-Key header is always treated as idempotent.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns policy which retries 3 times with jitter
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		BaseBackoff: defaultRetryBaseBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+		Jitter:      0.5,
+	}
+}
+
+// DefaultRetryOnError retries all errors except cancellation and deadline of context,
+// TimeoutError of phases set by Timeouts is retried.
+func DefaultRetryOnError(err error) bool {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		// next attempt has its own limit
+		return timeoutErr.Phase != TimeoutTotal
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// do sends req by doFunc, and retries while response is retryable.
+func (p *RetryPolicy) do(req *http.Request, doFunc func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	if maxAttempts < 2 || !p.canRetryMethod(req) {
+		return doFunc(req)
+	}
+
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		res, err := doFunc(attemptReq)
+		if attempt >= maxAttempts || !p.isRetryable(res, err) || !isReplayable(req) {
+			return res, err
+		}
+
+		wait := p.backoff(attempt)
+		if res != nil {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get(`Retry-After`), time.Now()); ok {
+				if retryAfter > p.maxBackoff() {
+					// server asks to wait too long
+					return res, err
+				}
+				wait = retryAfter
+			}
+		}
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			// never succeed before deadline
+			return res, err
+		}
+		if res != nil {
+			CloseBody(res.Body)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if attemptReq, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *RetryPolicy) canRetryMethod(req *http.Request) bool {
+	if p.RetryNonIdempotent {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(`Idempotency-Key`) != `` || req.Header.Get(`X-Idempotency-Key`) != ``
+}
+
+func (p *RetryPolicy) isRetryable(res *http.Response, err error) bool {
+	if err != nil {
+		if p.RetryOnError != nil {
+			return p.RetryOnError(err)
+		}
+		return DefaultRetryOnError(err)
+	}
+
+	statusCodes := p.StatusCodes
+	if statusCodes == nil {
+		statusCodes = defaultRetryStatusCodes
+	}
+	for _, code := range statusCodes {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns wait time before next attempt
+// maxBackoff returns MaxBackoff or its default
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return defaultRetryMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseBackoff
+	if base <= 0 {
+		base = defaultRetryBaseBackoff
+	}
+	max := p.maxBackoff()
+
+	wait := float64(base) * math.Pow(2, float64(attempt-1))
+	if wait > float64(max) {
+		wait = float64(max)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		wait -= wait * jitter * rand.Float64()
+	}
+	return time.Duration(wait)
+}
+
+// parseRetryAfter parses Retry-After header, which is seconds or http date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == `` {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// isReplayable reports whether request body can be sent again
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns copy of req which has new body
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	newReq := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return newReq, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	newReq.Body = body
+	return newReq, nil
+}
@@ -0,0 +1,308 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_client_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		policy       RetryPolicy
+		failures     int32
+		failureCode  int
+		wantAttempts int32
+		wantStatus   int
+	}{
+		{
+			name:         "retry_until_success",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			failures:     2,
+			failureCode:  http.StatusServiceUnavailable,
+			wantAttempts: 3,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "give_up_after_max_attempts",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+			failures:     5,
+			failureCode:  http.StatusBadGateway,
+			wantAttempts: 2,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "not_retryable_status_code",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			failures:     1,
+			failureCode:  http.StatusInternalServerError,
+			wantAttempts: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:         "custom_status_code",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond, StatusCodes: []int{500}},
+			failures:     1,
+			failureCode:  http.StatusInternalServerError,
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "default_max_attempts",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{BaseBackoff: time.Millisecond, StatusCodes: []int{500}},
+			failures:     5,
+			failureCode:  http.StatusInternalServerError,
+			wantAttempts: 4,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:         "single_attempt",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxAttempts: 1, BaseBackoff: time.Millisecond},
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "post_not_retried",
+			method:       http.MethodPost,
+			policy:       RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "post_retried_if_allowed",
+			method:       http.MethodPost,
+			policy:       RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond, RetryNonIdempotent: true},
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("failed to read body, %s", err)
+				}
+				if diff := cmp.Diff(string(body), "key=value"); diff != "" {
+					t.Errorf("invalid body, diff = %s", diff)
+				}
+				if atomic.AddInt32(&attempts, 1) <= tt.failures {
+					w.WriteHeader(tt.failureCode)
+					return
+				}
+				_, _ = w.Write([]byte("success"))
+			}))
+			defer server.Close()
+
+			res, err := Method(tt.method, server.URL).
+				Client(&http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				}}).
+				Retry(tt.policy).
+				URLEncoded("key", "value").
+				Execute()
+			if err != nil {
+				t.Fatalf("failed to execute %s", err)
+			}
+			defer CloseBody(res.Body)
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("invalid status code, got => %d, want => %d", res.StatusCode, tt.wantStatus)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("invalid attempts, got => %d, want => %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func Test_client_Retry_multipart(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := r.FormValue("key"); value != "value" {
+			t.Errorf("invalid form value, got => %s", value)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+
+	err := Post(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Retry(RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Hour, RetryNonIdempotent: true}).
+		MultipartData("key", strings.NewReader("value"), false).
+		HandleBody(func(body []uint8) error {
+			if string(body) != "success" {
+				t.Errorf("wrong response, got => %s", body)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("failed to post %s", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("invalid attempts, got => %d", got)
+	}
+}
+
+func Test_client_Retry_long_retry_after(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	res, err := Get(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Retry(RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second}).
+		Execute()
+	if err != nil {
+		t.Fatalf("failed to execute %s", err)
+	}
+	defer CloseBody(res.Body)
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("invalid status code, got => %d", res.StatusCode)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("retry must stop, got => %d attempts", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retry after longer than MaxBackoff must not be waited, elapsed %s", elapsed)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "first_retry",
+			policy:  RetryPolicy{BaseBackoff: time.Second, MaxBackoff: time.Minute},
+			attempt: 1,
+			wantMin: time.Second,
+			wantMax: time.Second,
+		},
+		{
+			name:    "exponential",
+			policy:  RetryPolicy{BaseBackoff: time.Second, MaxBackoff: time.Minute},
+			attempt: 3,
+			wantMin: 4 * time.Second,
+			wantMax: 4 * time.Second,
+		},
+		{
+			name:    "capped",
+			policy:  RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 3 * time.Second},
+			attempt: 5,
+			wantMin: 3 * time.Second,
+			wantMax: 3 * time.Second,
+		},
+		{
+			name:    "jitter",
+			policy:  RetryPolicy{BaseBackoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.5},
+			attempt: 2,
+			wantMin: time.Second,
+			wantMax: 2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.backoff(tt.attempt)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("backoff() = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 10, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "empty", value: "", want: 0, wantOK: false},
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "http_date", value: "Mon, 05 Oct 2020 12:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{name: "past_http_date", value: "Mon, 05 Oct 2020 11:00:00 GMT", want: 0, wantOK: true},
+		{name: "invalid", value: "soon", want: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_client_Retry_connection_reset(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("failed to hijack %s", err)
+				return
+			}
+			_ = conn.Close()
+			return
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+
+	res, err := Get(server.URL).
+		Client(&http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}).
+		Retry(RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}).
+		Execute()
+	if err != nil {
+		t.Fatalf("failed to get %s", err)
+	}
+	defer CloseBody(res.Body)
+
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("invalid attempts, got => %d", got)
+	}
+}
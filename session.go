@@ -0,0 +1,216 @@
+package gorest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is Client which keeps cookies across requests, like login and authenticated calls.
+// cookies can be saved into file as JSON and loaded, for restoring session after restart.
+// Session is safe for concurrent use.
+type Session struct {
+	*Client
+	jar *sessionJar
+}
+
+// NewSession requires base url and options for default settings like New.
+// http client passed by WithHTTPClient is never modified, its copy has cookie jar.
+func NewSession(baseURL string, opts ...Option) *Session {
+	c := New(baseURL, opts...)
+	jar := newSessionJar()
+
+	httpClient := http.DefaultClient
+	if c.defaults.client != nil {
+		httpClient = c.defaults.client
+	}
+	copied := *httpClient
+	copied.Jar = jar
+	c.defaults.client = &copied
+
+	return &Session{Client: c, jar: jar}
+}
+
+// Jar returns cookie jar of the session
+func (s *Session) Jar() http.CookieJar {
+	return s.jar
+}
+
+// Cookies returns cookies which are sent to rawURL
+func (s *Session) Cookies(rawURL string) ([]*http.Cookie, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return s.jar.Cookies(u), nil
+}
+
+// SaveCookies saves cookies into path as JSON, expired cookies are removed.
+// file is written atomically, and only owner can read it.
+func (s *Session) SaveCookies(path string) error {
+	data, err := json.MarshalIndent(s.jar.entries(time.Now()), ``, `  `)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, bytes.NewReader(data), 0600)
+}
+
+// LoadCookies loads cookies saved by SaveCookies, and adds them to the session
+func (s *Session) LoadCookies(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var entries []cookieEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	s.jar.restore(entries, time.Now())
+	return nil
+}
+
+// cookieEntry is cookie saved as JSON
+type cookieEntry struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain"`
+	HostOnly bool       `json:"host_only"`
+	Path     string     `json:"path"`
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"http_only,omitempty"`
+	SameSite int        `json:"same_site,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+}
+
+// sessionJar is cookiejar.Jar which records cookies, because cookiejar.Jar cannot list them
+type sessionJar struct {
+	jar *cookiejar.Jar
+
+	mu      sync.Mutex
+	cookies map[string]cookieEntry
+}
+
+func newSessionJar() *sessionJar {
+	// cookiejar.New never fails without options
+	jar, _ := cookiejar.New(nil)
+	return &sessionJar{jar: jar, cookies: map[string]cookieEntry{}}
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, cookie := range cookies {
+		entry, ok := newCookieEntry(u, cookie, now)
+		if !ok {
+			continue
+		}
+		key := entry.Domain + `;` + entry.Path + `;` + entry.Name
+		if entry.Expires != nil && !entry.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = entry
+	}
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// entries returns recorded cookies which are not expired
+func (j *sessionJar) entries(now time.Time) []cookieEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]cookieEntry, 0, len(j.cookies))
+	for key, entry := range j.cookies {
+		if entry.Expires != nil && !entry.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// restore adds entries to jar
+func (j *sessionJar) restore(entries []cookieEntry, now time.Time) {
+	for _, entry := range entries {
+		if entry.Expires != nil && !entry.Expires.After(now) {
+			continue
+		}
+		u := &url.URL{Scheme: `http`, Host: entry.Domain, Path: entry.Path}
+		if entry.Secure {
+			u.Scheme = `https`
+		}
+		cookie := &http.Cookie{
+			Name:     entry.Name,
+			Value:    entry.Value,
+			Path:     entry.Path,
+			Secure:   entry.Secure,
+			HttpOnly: entry.HttpOnly,
+			SameSite: http.SameSite(entry.SameSite),
+		}
+		if !entry.HostOnly {
+			cookie.Domain = entry.Domain
+		}
+		if entry.Expires != nil {
+			cookie.Expires = *entry.Expires
+		}
+		j.SetCookies(u, []*http.Cookie{cookie})
+	}
+}
+
+// newCookieEntry returns cookie with domain, path and expiration decided by RFC 6265,
+// false if cookie is not for u
+func newCookieEntry(u *url.URL, cookie *http.Cookie, now time.Time) (cookieEntry, bool) {
+	host := strings.ToLower(u.Hostname())
+	entry := cookieEntry{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   host,
+		HostOnly: true,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		SameSite: int(cookie.SameSite),
+	}
+
+	if domain := strings.TrimPrefix(strings.ToLower(cookie.Domain), `.`); domain != `` && domain != host {
+		if !strings.HasSuffix(host, `.`+domain) {
+			return cookieEntry{}, false
+		}
+		entry.Domain = domain
+		entry.HostOnly = false
+	} else if domain != `` {
+		entry.HostOnly = false
+	}
+
+	if entry.Path == `` || !strings.HasPrefix(entry.Path, `/`) {
+		entry.Path = `/`
+		if i := strings.LastIndex(u.Path, `/`); i > 0 {
+			entry.Path = u.Path[:i]
+		}
+	}
+
+	switch {
+	case cookie.MaxAge < 0:
+		expires := time.Unix(1, 0)
+		entry.Expires = &expires
+	case cookie.MaxAge > 0:
+		expires := now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		entry.Expires = &expires
+	case !cookie.Expires.IsZero():
+		expires := cookie.Expires
+		entry.Expires = &expires
+	}
+	return entry, true
+}
@@ -0,0 +1,153 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newSessionServer returns server which issues session cookie by /login, and removes it by /logout
+func newSessionServer(t *testing.T) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/", HttpOnly: true, MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/"})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "session", Path: "/", MaxAge: -1})
+		case "/me":
+			cookie, err := r.Cookie("session")
+			if err != nil || cookie.Value != "s3cr3t" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(r.Header.Get("Cookie")))
+		}
+	}))
+}
+
+func testSessionHTTPClient() *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+}
+
+func TestSession(t *testing.T) {
+	server := newSessionServer(t)
+	defer server.Close()
+	httpClient := testSessionHTTPClient()
+
+	session := NewSession(server.URL, WithHTTPClient(httpClient))
+	if httpClient.Jar != nil {
+		t.Error("http client must not be modified")
+	}
+	if _, err := session.Post().Path("/login").ExpectStatus(http.StatusOK).Execute(); err != nil {
+		t.Fatalf("failed to login %s", err)
+	}
+
+	var got string
+	err := session.Get().Path("/me").Cookie(&http.Cookie{Name: "lang", Value: "ja"}).
+		HandleBody(func(body []uint8) error {
+			got = string(body)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("session cookie must be sent, %s", err)
+	}
+	if diff := cmp.Diff(got, "lang=ja; session=s3cr3t; theme=dark"); diff != "" {
+		t.Errorf("invalid cookies, diff = %s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := session.SaveCookies(path); err != nil {
+		t.Fatalf("failed to save cookies %s", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("cookies must be readable only by owner, got => %s", info.Mode())
+	}
+
+	restored := NewSession(server.URL, WithHTTPClient(httpClient))
+	if err := restored.LoadCookies(path); err != nil {
+		t.Fatalf("failed to load cookies %s", err)
+	}
+	if _, err := restored.Get().Path("/me").ExpectStatus(http.StatusOK).Execute(); err != nil {
+		t.Errorf("restored session cookie must be sent, %s", err)
+	}
+
+	if _, err := restored.Post().Path("/logout").Execute(); err != nil {
+		t.Fatalf("failed to logout %s", err)
+	}
+	if err := restored.SaveCookies(path); err != nil {
+		t.Fatalf("failed to save cookies %s", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []cookieEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "theme" {
+		t.Errorf("removed cookie must not be saved, got => %s", data)
+	}
+}
+
+func TestSession_LoadCookies_not_found(t *testing.T) {
+	session := NewSession("https://sample.com")
+	if err := session.LoadCookies(filepath.Join(t.TempDir(), "not_found.json")); !os.IsNotExist(err) {
+		t.Errorf("not exist error must be returned, got => %v", err)
+	}
+}
+
+func Test_newCookieEntry(t *testing.T) {
+	u, _ := url.Parse("https://api.example.com/v1/users")
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		want   cookieEntry
+		wantOK bool
+	}{
+		{
+			name:   "host_only_with_default_path",
+			cookie: &http.Cookie{Name: "a", Value: "1"},
+			want:   cookieEntry{Name: "a", Value: "1", Domain: "api.example.com", HostOnly: true, Path: "/v1"},
+			wantOK: true,
+		},
+		{
+			name:   "domain",
+			cookie: &http.Cookie{Name: "a", Value: "1", Domain: ".example.com", Path: "/"},
+			want:   cookieEntry{Name: "a", Value: "1", Domain: "example.com", Path: "/"},
+			wantOK: true,
+		},
+		{
+			name:   "other_domain",
+			cookie: &http.Cookie{Name: "a", Value: "1", Domain: "other.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newCookieEntry(u, tt.cookie, time.Now())
+			if ok != tt.wantOK {
+				t.Fatalf("ok => %v, want => %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("newCookieEntry() diff = %s", diff)
+			}
+		})
+	}
+}
@@ -1,18 +1,45 @@
 package gorest
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 func (cli *client) Path(pathFmt string, args ...interface{}) TerminalOperator {
-	cli.paths = append(cli.paths, fmt.Sprintf(pathFmt, args...))
+	cli.paths = append(cli.paths, formatPath(pathFmt, args...))
+	return cli
+}
+
+func (cli *client) PathParam(key string, value string) TerminalOperator {
+	if cli.pathParams == nil {
+		cli.pathParams = map[string]string{}
+	}
+	cli.pathParams[key] = value
 	return cli
 }
 
 func (cli *client) URLParam(key string, value string) TerminalOperator {
-	cli.urlParams = append(cli.urlParams, fmt.Sprintf("%s=%s", key, value))
+	if cli.query == nil {
+		cli.query = url.Values{}
+	}
+	cli.query.Add(key, value)
+	return cli
+}
+
+func (cli *client) URLParams(params map[string][]string) TerminalOperator {
+	if cli.query == nil {
+		cli.query = url.Values{}
+	}
+	for key, values := range params {
+		cli.query[key] = append(cli.query[key], values...)
+	}
+	return cli
+}
+
+func (cli *client) QueryStruct(v interface{}) TerminalOperator {
+	cli.queryStructs = append(cli.queryStructs, v)
 	return cli
 }
 
@@ -22,11 +49,66 @@ func (cli *client) BasicAuth(username string, password string) TerminalOperator
 	return cli
 }
 
+func (cli *client) DigestAuth(username string, password string) TerminalOperator {
+	cli.digestAuth = newDigestAuth(username, password)
+	return cli
+}
+
+func (cli *client) BearerToken(token string) TerminalOperator {
+	cli.bearerToken = &token
+	return cli
+}
+
+func (cli *client) BearerTokenSource(source TokenSource) TerminalOperator {
+	cli.tokenSource = source
+	return cli
+}
+
+func (cli *client) Sign(signer Signer) TerminalOperator {
+	cli.signer = signer
+	return cli
+}
+
 func (cli *client) Header(key, value string) TerminalOperator {
-	if len(cli.headers) == 0 {
-		cli.headers = map[string]string{}
+	if cli.headers == nil {
+		cli.headers = http.Header{}
+	}
+	cli.headers.Set(key, value)
+	return cli
+}
+
+func (cli *client) AddHeader(key, value string) TerminalOperator {
+	if cli.headers == nil {
+		cli.headers = http.Header{}
+	}
+	cli.headers.Add(key, value)
+	return cli
+}
+
+func (cli *client) Headers(headers http.Header) TerminalOperator {
+	if cli.headers == nil {
+		cli.headers = http.Header{}
+	}
+	for key, values := range headers {
+		cli.headers[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
 	}
-	cli.headers[key] = value
+	return cli
+}
+
+func (cli *client) Accept(mediaTypes ...string) TerminalOperator {
+	return cli.Headers(http.Header{`Accept`: mediaTypes})
+}
+
+func (cli *client) UserAgent(userAgent string) TerminalOperator {
+	return cli.Header(`User-Agent`, userAgent)
+}
+
+func (cli *client) ContentType(contentType string) TerminalOperator {
+	return cli.Header(`Content-Type`, contentType)
+}
+
+func (cli *client) Cookie(cookie *http.Cookie) TerminalOperator {
+	cli.cookies = append(cli.cookies, cookie)
 	return cli
 }
 
@@ -35,6 +117,81 @@ func (cli *client) Client(client *http.Client) TerminalOperator {
 	return cli
 }
 
+func (cli *client) Context(ctx context.Context) TerminalOperator {
+	cli.ctx = ctx
+	return cli
+}
+
+func (cli *client) Timeout(timeout time.Duration) TerminalOperator {
+	cli.timeout = timeout
+	return cli
+}
+
+func (cli *client) Timeouts(timeouts Timeouts) TerminalOperator {
+	cli.timeouts = timeouts
+	return cli
+}
+
+func (cli *client) Retry(policy RetryPolicy) TerminalOperator {
+	cli.retryPolicy = &policy
+	return cli
+}
+
+func (cli *client) Log(logger Logger, options LogOptions) TerminalOperator {
+	cli.logger = newRequestLogger(logger, options)
+	return cli
+}
+
+func (cli *client) Use(middlewares ...Middleware) TerminalOperator {
+	cli.middlewares = append(cli.middlewares, middlewares...)
+	return cli
+}
+
+func (cli *client) CompressBody(encoding Encoding) TerminalOperator {
+	cli.compression = encoding
+	return cli
+}
+
+func (cli *client) OnUploadProgress(f func(sent, total int64)) TerminalOperator {
+	cli.uploadProgress = f
+	return cli
+}
+
+func (cli *client) OnDownloadProgress(f func(received, total int64)) TerminalOperator {
+	cli.downloadProgress = f
+	return cli
+}
+
+func (cli *client) ExpectStatus(codes ...int) TerminalOperator {
+	cli.expectedStatuses = append(cli.expectedStatuses, codes...)
+	return cli
+}
+
+func (cli *client) AcceptStatus(f func(code int) bool) TerminalOperator {
+	cli.acceptStatus = f
+	return cli
+}
+
+func (cli *client) NoRedirect() TerminalOperator {
+	cli.noRedirect = true
+	return cli
+}
+
+func (cli *client) OnError(model interface{}) TerminalOperator {
+	cli.errorModel = model
+	return cli
+}
+
+func (cli *client) MaxErrorBody(size int64) TerminalOperator {
+	cli.maxErrorBodySize = size
+	return cli
+}
+
+func (cli *client) RedactErrorBody(f func(body []byte) []byte) TerminalOperator {
+	cli.errorBodyRedactor = f
+	return cli
+}
+
 func (cli *client) JSON(json []byte) JSONContent {
 	if len(json) != 0 {
 		cli.params = json
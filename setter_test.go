@@ -1,6 +1,7 @@
 package gorest
 
 import (
+	"context"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"io"
@@ -17,7 +18,7 @@ func Test_client_Path(t *testing.T) {
 		contentType contentType
 		baseURL     string
 		paths       []string
-		urlParams   []string
+		query       url.Values
 	}
 	type args struct {
 		pathFmt string
@@ -94,7 +95,7 @@ func Test_client_Path(t *testing.T) {
 				contentType: tt.fields.contentType,
 				baseURL:     tt.fields.baseURL,
 				paths:       tt.fields.paths,
-				urlParams:   tt.fields.urlParams,
+				query:       tt.fields.query,
 			}
 			if got := cli.Path(tt.args.pathFmt, tt.args.args...); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Path() = %v, want %v", got, tt.want)
@@ -109,7 +110,7 @@ func Test_client_URLParam(t *testing.T) {
 		contentType contentType
 		baseURL     string
 		paths       []string
-		urlParams   []string
+		query       url.Values
 	}
 	type args struct {
 		key   string
@@ -128,7 +129,7 @@ func Test_client_URLParam(t *testing.T) {
 				contentType: "",
 				baseURL:     "https://sample.com",
 				paths:       []string{"/users"},
-				urlParams:   nil,
+				query:       nil,
 			},
 			args: args{
 				key:   "key",
@@ -139,7 +140,7 @@ func Test_client_URLParam(t *testing.T) {
 				contentType: "",
 				baseURL:     "https://sample.com",
 				paths:       []string{"/users"},
-				urlParams:   []string{"key=value"},
+				query:       url.Values{"key": []string{"value"}},
 			},
 		},
 		{
@@ -149,7 +150,7 @@ func Test_client_URLParam(t *testing.T) {
 				contentType: "",
 				baseURL:     "https://sample.com",
 				paths:       []string{"/users"},
-				urlParams:   []string{"key=value"},
+				query:       url.Values{"key": []string{"value"}},
 			},
 			args: args{
 				key:   "key2",
@@ -160,7 +161,28 @@ func Test_client_URLParam(t *testing.T) {
 				contentType: "",
 				baseURL:     "https://sample.com",
 				paths:       []string{"/users"},
-				urlParams:   []string{"key=value", "key2=value2"},
+				query:       url.Values{"key": []string{"value"}, "key2": []string{"value2"}},
+			},
+		},
+		{
+			name: "repeat_same_key",
+			fields: fields{
+				method:      "GET",
+				contentType: "",
+				baseURL:     "https://sample.com",
+				paths:       []string{"/users"},
+				query:       url.Values{"key": []string{"value"}},
+			},
+			args: args{
+				key:   "key",
+				value: "a&b c+d",
+			},
+			want: &client{
+				method:      "GET",
+				contentType: "",
+				baseURL:     "https://sample.com",
+				paths:       []string{"/users"},
+				query:       url.Values{"key": []string{"value", "a&b c+d"}},
 			},
 		},
 	}
@@ -171,7 +193,7 @@ func Test_client_URLParam(t *testing.T) {
 				contentType: tt.fields.contentType,
 				baseURL:     tt.fields.baseURL,
 				paths:       tt.fields.paths,
-				urlParams:   tt.fields.urlParams,
+				query:       tt.fields.query,
 			}
 			if got := cli.URLParam(tt.args.key, tt.args.value); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("URLParam() = %v, want %v", got, tt.want)
@@ -189,7 +211,7 @@ func Test_client_BasicAuth(t *testing.T) {
 		method      requestMethod
 		contentType contentType
 		baseURL     string
-		headers     map[string]string
+		headers     http.Header
 		username    *string
 		password    *string
 	}
@@ -230,7 +252,7 @@ func Test_client_BasicAuth(t *testing.T) {
 				method:      "POST",
 				contentType: "",
 				baseURL:     "https://sample.com",
-				headers:     map[string]string{"key": "value"},
+				headers:     http.Header{"Key": {"value"}},
 				username:    nil,
 				password:    nil,
 			},
@@ -242,7 +264,7 @@ func Test_client_BasicAuth(t *testing.T) {
 				method:      "POST",
 				contentType: "",
 				baseURL:     "https://sample.com",
-				headers:     map[string]string{"key": "value"},
+				headers:     http.Header{"Key": {"value"}},
 				username:    strPtr("name"),
 				password:    strPtr("pass"),
 			},
@@ -273,7 +295,7 @@ func Test_client_Header(t *testing.T) {
 		baseURL     string
 		username    *string
 		password    *string
-		headers     map[string]string
+		headers     http.Header
 	}
 	type args struct {
 		key   string
@@ -305,7 +327,7 @@ func Test_client_Header(t *testing.T) {
 				baseURL:     "https://sample.com",
 				username:    strPtr("user"),
 				password:    strPtr("pass"),
-				headers:     map[string]string{"key": "value"},
+				headers:     http.Header{"Key": {"value"}},
 			},
 		},
 		{
@@ -316,7 +338,7 @@ func Test_client_Header(t *testing.T) {
 				baseURL:     "https://sample.com",
 				username:    strPtr("user"),
 				password:    strPtr("pass"),
-				headers:     map[string]string{"key": "value"},
+				headers:     http.Header{"Key": {"value"}},
 			},
 			args: args{
 				key:   "key2",
@@ -328,7 +350,7 @@ func Test_client_Header(t *testing.T) {
 				baseURL:     "https://sample.com",
 				username:    strPtr("user"),
 				password:    strPtr("pass"),
-				headers:     map[string]string{"key": "value", "key2": "value2"},
+				headers:     http.Header{"Key": {"value"}, "Key2": {"value2"}},
 			},
 		},
 		{
@@ -339,7 +361,7 @@ func Test_client_Header(t *testing.T) {
 				baseURL:     "https://sample.com",
 				username:    strPtr("user"),
 				password:    strPtr("pass"),
-				headers:     map[string]string{"key": "value"},
+				headers:     http.Header{"Key": {"value"}},
 			},
 			args: args{
 				key:   "key",
@@ -351,7 +373,7 @@ func Test_client_Header(t *testing.T) {
 				baseURL:     "https://sample.com",
 				username:    strPtr("user"),
 				password:    strPtr("pass"),
-				headers:     map[string]string{"key": "value2"},
+				headers:     http.Header{"Key": {"value2"}},
 			},
 		},
 	}
@@ -372,6 +394,53 @@ func Test_client_Header(t *testing.T) {
 	}
 }
 
+func Test_client_header_helpers(t *testing.T) {
+	tests := []struct {
+		name string
+		got  TerminalOperator
+		want http.Header
+	}{
+		{
+			name: "add_header",
+			got:  (&client{}).AddHeader("accept", "text/html").AddHeader("Accept", "application/json"),
+			want: http.Header{"Accept": {"text/html", "application/json"}},
+		},
+		{
+			name: "header_replaces_added",
+			got:  (&client{}).AddHeader("Accept", "text/html").Header("Accept", "application/json"),
+			want: http.Header{"Accept": {"application/json"}},
+		},
+		{
+			name: "headers",
+			got: (&client{}).Header("X-Api-Key", "token").Header("Cookie", "a=1").
+				Headers(http.Header{"cookie": {"b=2", "c=3"}, "X-Request-Id": {"1"}}),
+			want: http.Header{"X-Api-Key": {"token"}, "Cookie": {"b=2", "c=3"}, "X-Request-Id": {"1"}},
+		},
+		{
+			name: "accept",
+			got:  (&client{}).Accept("application/json", "text/plain"),
+			want: http.Header{"Accept": {"application/json", "text/plain"}},
+		},
+		{
+			name: "user_agent",
+			got:  (&client{}).UserAgent("gorest/1.0"),
+			want: http.Header{"User-Agent": {"gorest/1.0"}},
+		},
+		{
+			name: "content_type",
+			got:  (&client{}).ContentType("application/merge-patch+json"),
+			want: http.Header{"Content-Type": {"application/merge-patch+json"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.got.(*client).headers, tt.want); diff != "" {
+				t.Errorf("headers diff = %s", diff)
+			}
+		})
+	}
+}
+
 func Test_client_JSON(t *testing.T) {
 	type fields struct {
 		method        requestMethod
@@ -859,3 +928,160 @@ func Test_client_HandleResponse(t *testing.T) {
 		})
 	}
 }
+
+func Test_client_Context(t *testing.T) {
+	ctx := context.WithValue(context.Background(), struct{}{}, "value")
+
+	type fields struct {
+		method  requestMethod
+		baseURL string
+		ctx     context.Context
+	}
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   TerminalOperator
+	}{
+		{
+			name: "simple_set",
+			fields: fields{
+				method:  "GET",
+				baseURL: "https://sample.com",
+				ctx:     nil,
+			},
+			args: args{
+				ctx: ctx,
+			},
+			want: &client{
+				method:  "GET",
+				baseURL: "https://sample.com",
+				ctx:     ctx,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &client{
+				method:  tt.fields.method,
+				baseURL: tt.fields.baseURL,
+				ctx:     tt.fields.ctx,
+			}
+			if got := cli.Context(tt.args.ctx); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Context() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_URLParams(t *testing.T) {
+	type fields struct {
+		query url.Values
+	}
+	type args struct {
+		params map[string][]string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   TerminalOperator
+	}{
+		{
+			name: "simple_set",
+			fields: fields{
+				query: nil,
+			},
+			args: args{
+				params: map[string][]string{"key": {"value", "value2"}},
+			},
+			want: &client{
+				query: url.Values{"key": []string{"value", "value2"}},
+			},
+		},
+		{
+			name: "add_if_same_key",
+			fields: fields{
+				query: url.Values{"key": []string{"value"}},
+			},
+			args: args{
+				params: map[string][]string{"key": {"value2"}, "key2": {"value3"}},
+			},
+			want: &client{
+				query: url.Values{"key": []string{"value", "value2"}, "key2": []string{"value3"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &client{
+				query: tt.fields.query,
+			}
+			if got := cli.URLParams(tt.args.params); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("URLParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_PathParam(t *testing.T) {
+	type fields struct {
+		paths      []string
+		pathParams map[string]string
+	}
+	type args struct {
+		key   string
+		value string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   TerminalOperator
+	}{
+		{
+			name: "simple_set",
+			fields: fields{
+				paths:      []string{"/repos/{owner}"},
+				pathParams: nil,
+			},
+			args: args{
+				key:   "owner",
+				value: "izumix03",
+			},
+			want: &client{
+				paths:      []string{"/repos/{owner}"},
+				pathParams: map[string]string{"owner": "izumix03"},
+			},
+		},
+		{
+			name: "update_if_same_key",
+			fields: fields{
+				paths:      []string{"/repos/{owner}"},
+				pathParams: map[string]string{"owner": "izumix03"},
+			},
+			args: args{
+				key:   "owner",
+				value: "other",
+			},
+			want: &client{
+				paths:      []string{"/repos/{owner}"},
+				pathParams: map[string]string{"owner": "other"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &client{
+				paths:      tt.fields.paths,
+				pathParams: tt.fields.pathParams,
+			}
+			if got := cli.PathParam(tt.args.key, tt.args.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package gorest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Signer signs request after it is fully built, like setting signature header.
+// body returns request body, it is buffered only when body is called.
+// middlewares run after signing, so they must not modify signed parts of request.
+type Signer interface {
+	Sign(req *http.Request, body func() ([]byte, error)) error
+}
+
+// SignerFunc is an adapter to use function as Signer
+type SignerFunc func(req *http.Request, body func() ([]byte, error)) error
+
+// Sign calls f(req, body)
+func (f SignerFunc) Sign(req *http.Request, body func() ([]byte, error)) error {
+	return f(req, body)
+}
+
+// sign signs req by signer of cli
+func (cli *client) sign(req *http.Request) error {
+	if cli.signer == nil {
+		return nil
+	}
+	return cli.signer.Sign(req, bufferBody(req))
+}
+
+// bufferBody returns function which reads request body once.
+// if body cannot be read again, it is replaced by the buffer.
+func bufferBody(req *http.Request) func() ([]byte, error) {
+	var buffered []byte
+	return func() ([]byte, error) {
+		if buffered != nil {
+			return buffered, nil
+		}
+		if req.Body == nil || req.Body == http.NoBody {
+			buffered = []byte{}
+			return buffered, nil
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = body.Close() }()
+			if buffered, err = ioutil.ReadAll(body); err != nil {
+				return nil, err
+			}
+			return buffered, nil
+		}
+
+		// body like streaming multipart is read only once
+		defer func() { _ = req.Body.Close() }()
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		buffered = data
+		req.Body = ioutil.NopCloser(bytes.NewReader(buffered))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(buffered)), nil
+		}
+		req.ContentLength = int64(len(buffered))
+		return buffered, nil
+	}
+}
+
+const (
+	hmacAlgorithm         = `HMAC-SHA256`
+	hmacDateHeader        = `X-Date`
+	hmacContentHashHeader = `X-Content-Sha256`
+	signingTimeFormat     = `20060102T150405Z`
+)
+
+// HMACSigner signs request by HMAC-SHA256 over method, path, sorted query,
+// signed headers and hash of body, and sets
+// `Authorization: HMAC-SHA256 Credential=keyID, SignedHeaders=host;x-content-sha256;x-date, Signature=...`.
+// X-Date and X-Content-Sha256 headers are also set.
+//
+// canonical request for the signature is
+//
+//	METHOD
+//	/escaped/path
+//	sorted=query&key=value
+//	host:example.com
+//	x-content-sha256:hash of body
+//	x-date:20060102T150405Z
+//
+//	host;x-content-sha256;x-date
+//	hash of body
+type HMACSigner struct {
+	KeyID  string
+	Secret []byte
+	// SignedHeaders are additional headers for the signature, like `Content-Type`.
+	// host, x-date and x-content-sha256 are always signed.
+	SignedHeaders []string
+
+	now func() time.Time
+}
+
+func (s *HMACSigner) Sign(req *http.Request, body func() ([]byte, error)) error {
+	payload, err := body()
+	if err != nil {
+		return err
+	}
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set(hmacDateHeader, signingTime(s.now).Format(signingTimeFormat))
+	req.Header.Set(hmacContentHashHeader, payloadHash)
+
+	signedHeaders := append([]string{`host`, `x-date`, `x-content-sha256`}, s.SignedHeaders...)
+	canonicalHeaders, signedHeaderNames := canonicalizeHeaders(req, signedHeaders)
+	canonical := canonicalRequest(req.Method, req.URL.EscapedPath(), canonicalizeQuery(req.URL),
+		canonicalHeaders, signedHeaderNames, payloadHash)
+
+	signature := hex.EncodeToString(hmacSHA256(s.Secret, []byte(canonical)))
+	req.Header.Set(`Authorization`, hmacAlgorithm+` Credential=`+s.KeyID+
+		`, SignedHeaders=`+signedHeaderNames+`, Signature=`+signature)
+	return nil
+}
+
+const (
+	awsV4Algorithm       = `AWS4-HMAC-SHA256`
+	awsUnsignedPayload   = `UNSIGNED-PAYLOAD`
+	awsDateHeader        = `X-Amz-Date`
+	awsContentHashHeader = `X-Amz-Content-Sha256`
+	awsTokenHeader       = `X-Amz-Security-Token`
+)
+
+// AWSV4Signer signs request by AWS Signature Version 4.
+// host, content-type, x-amz-* headers and SignedHeaders are signed.
+type AWSV4Signer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set to X-Amz-Security-Token header if it is not empty
+	SessionToken string
+	Region       string
+	Service      string
+	// SignedHeaders are additional headers for the signature
+	SignedHeaders []string
+	// UnsignedPayload never reads request body and signs `UNSIGNED-PAYLOAD` instead of hash of body,
+	// for large body like S3 upload.
+	UnsignedPayload bool
+
+	now func() time.Time
+}
+
+func (s *AWSV4Signer) Sign(req *http.Request, body func() ([]byte, error)) error {
+	payloadHash := awsUnsignedPayload
+	if !s.UnsignedPayload {
+		payload, err := body()
+		if err != nil {
+			return err
+		}
+		payloadHash = sha256Hex(payload)
+	}
+
+	signedAt := signingTime(s.now)
+	amzDate := signedAt.Format(signingTimeFormat)
+	req.Header.Set(awsDateHeader, amzDate)
+	if s.Service == `s3` || s.UnsignedPayload {
+		req.Header.Set(awsContentHashHeader, payloadHash)
+	}
+	if s.SessionToken != `` {
+		req.Header.Set(awsTokenHeader, s.SessionToken)
+	}
+
+	signedHeaders := []string{`host`, `content-type`}
+	for key := range req.Header {
+		if strings.HasPrefix(strings.ToLower(key), `x-amz-`) {
+			signedHeaders = append(signedHeaders, key)
+		}
+	}
+	signedHeaders = append(signedHeaders, s.SignedHeaders...)
+	canonicalHeaders, signedHeaderNames := canonicalizeHeaders(req, signedHeaders)
+
+	canonical := canonicalRequest(req.Method, awsCanonicalPath(req.URL, s.Service), canonicalizeQuery(req.URL),
+		canonicalHeaders, signedHeaderNames, payloadHash)
+
+	date := signedAt.Format(`20060102`)
+	scope := strings.Join([]string{date, s.Region, s.Service, `aws4_request`}, `/`)
+	stringToSign := strings.Join([]string{awsV4Algorithm, amzDate, scope, sha256Hex([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte(`AWS4`+s.SecretAccessKey), []byte(date))
+	for _, v := range []string{s.Region, s.Service, `aws4_request`} {
+		key = hmacSHA256(key, []byte(v))
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+
+	req.Header.Set(`Authorization`, awsV4Algorithm+` Credential=`+s.AccessKeyID+`/`+scope+
+		`, SignedHeaders=`+signedHeaderNames+`, Signature=`+signature)
+	return nil
+}
+
+// awsCanonicalPath escapes each segment of path by RFC 3986 once for s3, and twice for other services.
+// escaped slash in segment is kept.
+func awsCanonicalPath(u *url.URL, service string) string {
+	segments := strings.Split(u.EscapedPath(), `/`)
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = escapeRFC3986(segment, false)
+		if service != `s3` {
+			segment = escapeRFC3986(segment, false)
+		}
+		segments[i] = segment
+	}
+	return strings.Join(segments, `/`)
+}
+
+// canonicalRequest joins parts of request for signature
+func canonicalRequest(method, path, query, headers, signedHeaders, payloadHash string) string {
+	if path == `` {
+		path = `/`
+	}
+	return strings.Join([]string{method, path, query, headers, signedHeaders, payloadHash}, "\n")
+}
+
+// canonicalizeQuery escapes query by RFC 3986, and sorts it by key and then by value
+func canonicalizeQuery(u *url.URL) string {
+	query := u.Query()
+	escaped := make(map[string][]string, len(query))
+	keys := make([]string, 0, len(query))
+	for key, values := range query {
+		key = escapeRFC3986(key, false)
+		keys = append(keys, key)
+		for _, value := range values {
+			escaped[key] = append(escaped[key], escapeRFC3986(value, false))
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := escaped[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, key+`=`+value)
+		}
+	}
+	return strings.Join(pairs, `&`)
+}
+
+// canonicalizeHeaders returns lines of `name:value` sorted by name, and joined names of them.
+// headers which have no value are ignored.
+func canonicalizeHeaders(req *http.Request, names []string) (string, string) {
+	values := map[string]string{}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if name == `host` {
+			host := req.Host
+			if host == `` {
+				host = req.URL.Host
+			}
+			values[name] = host
+			continue
+		}
+		headerValues := req.Header.Values(name)
+		if len(headerValues) == 0 {
+			continue
+		}
+		trimmed := make([]string, 0, len(headerValues))
+		for _, v := range headerValues {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), ` `))
+		}
+		if value := strings.Join(trimmed, `,`); value != `` {
+			values[name] = value
+		}
+	}
+
+	signedNames := make([]string, 0, len(values))
+	for name := range values {
+		signedNames = append(signedNames, name)
+	}
+	sort.Strings(signedNames)
+
+	var lines strings.Builder
+	for _, name := range signedNames {
+		lines.WriteString(name + `:` + values[name] + "\n")
+	}
+	return lines.String(), strings.Join(signedNames, `;`)
+}
+
+// escapeRFC3986 escapes s except unreserved characters, and slash if keepSlash
+func escapeRFC3986(s string, keepSlash bool) string {
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			escaped.WriteByte(c)
+			continue
+		}
+		escaped.WriteString(`%` + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return escaped.String()
+}
+
+func signingTime(now func() time.Time) time.Time {
+	if now == nil {
+		return time.Now().UTC()
+	}
+	return now().UTC()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
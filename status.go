@@ -0,0 +1,23 @@
+package gorest
+
+// isValidStatus reports whether status code is valid by status policy.
+// default policy is that status code under 400 is valid.
+func (cli *client) isValidStatus(code int) bool {
+	if cli.expectedStatuses != nil || cli.acceptStatus != nil {
+		for _, expected := range cli.expectedStatuses {
+			if code == expected {
+				return true
+			}
+		}
+		return cli.acceptStatus != nil && cli.acceptStatus(code)
+	}
+	if cli.noRedirect && code >= 300 && code < 400 {
+		return false
+	}
+	return code < 400
+}
+
+// hasStatusPolicy reports whether status policy is set explicitly
+func (cli *client) hasStatusPolicy() bool {
+	return cli.expectedStatuses != nil || cli.acceptStatus != nil || cli.noRedirect
+}
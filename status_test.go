@@ -0,0 +1,135 @@
+package gorest
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_client_isValidStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		cli  *client
+		code int
+		want bool
+	}{
+		{name: "default_ok", cli: &client{}, code: 200, want: true},
+		{name: "default_redirect", cli: &client{}, code: 302, want: true},
+		{name: "default_not_found", cli: &client{}, code: 404, want: false},
+		{name: "expect_not_found", cli: &client{expectedStatuses: []int{200, 404}}, code: 404, want: true},
+		{name: "expect_created", cli: &client{expectedStatuses: []int{201}}, code: 200, want: false},
+		{
+			name: "accept_func",
+			cli:  &client{acceptStatus: func(code int) bool { return code < 500 }},
+			code: 409,
+			want: true,
+		},
+		{
+			name: "expect_or_accept",
+			cli: &client{
+				expectedStatuses: []int{503},
+				acceptStatus:     func(code int) bool { return code < 300 },
+			},
+			code: 503,
+			want: true,
+		},
+		{name: "no_redirect", cli: &client{noRedirect: true}, code: 301, want: false},
+		{name: "no_redirect_expected", cli: &client{noRedirect: true, expectedStatuses: []int{301}}, code: 301, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cli.isValidStatus(tt.code); got != tt.want {
+				t.Errorf("isValidStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_ExpectStatus(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("success"))
+		}
+	}))
+	defer server.Close()
+
+	base := New(server.URL, WithHTTPClient(&http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}))
+
+	t.Run("not_found_is_valid", func(t *testing.T) {
+		err := base.Get().
+			Path("/missing").
+			ExpectStatus(http.StatusOK, http.StatusNotFound).
+			HandleBody(func(body []uint8) error { return nil })
+		if err != nil {
+			t.Errorf("404 must be valid, got => %s", err)
+		}
+	})
+
+	t.Run("require_created", func(t *testing.T) {
+		res, err := base.Post().
+			Path("/ok").
+			ExpectStatus(http.StatusCreated).
+			Execute()
+		var statusErr *InvalidStatusCodeError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusOK {
+			t.Errorf("200 must be invalid, got => %v", err)
+		}
+		if res != nil {
+			t.Error("response must be nil")
+		}
+	})
+
+	t.Run("execute_default_not_validated", func(t *testing.T) {
+		res, err := base.Get().
+			Path("/missing").
+			Execute()
+		if err != nil {
+			t.Fatalf("status code must not be validated, got => %s", err)
+		}
+		CloseBody(res.Body)
+	})
+
+	t.Run("redirect_is_followed", func(t *testing.T) {
+		err := base.Get().
+			Path("/redirect").
+			HandleBody(func(body []uint8) error { return nil })
+		if err != nil {
+			t.Errorf("redirect must be followed, got => %s", err)
+		}
+	})
+
+	t.Run("redirect_is_error", func(t *testing.T) {
+		_, err := base.Get().
+			Path("/redirect").
+			NoRedirect().
+			Execute()
+		var statusErr *InvalidStatusCodeError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusFound {
+			t.Errorf("302 must be invalid, got => %v", err)
+		}
+	})
+
+	t.Run("expected_redirect", func(t *testing.T) {
+		res, err := base.Get().
+			Path("/redirect").
+			NoRedirect().
+			ExpectStatus(http.StatusFound).
+			Execute()
+		if err != nil {
+			t.Fatalf("302 must be valid, got => %s", err)
+		}
+		defer CloseBody(res.Body)
+		if res.Header.Get("Location") != "/ok" {
+			t.Errorf("invalid location, got => %s", res.Header.Get("Location"))
+		}
+	})
+}
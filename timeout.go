@@ -0,0 +1,243 @@
+package gorest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// TimeoutPhase is phase of api call limited by timeout
+type TimeoutPhase string
+
+const (
+	// TimeoutTotal is whole api call including retries and reading response body, limited by Timeout
+	TimeoutTotal TimeoutPhase = "total"
+	// TimeoutAttempt is each attempt until response header is received
+	TimeoutAttempt TimeoutPhase = "attempt"
+	// TimeoutConnect is dial and TLS handshake
+	TimeoutConnect TimeoutPhase = "connect"
+	// TimeoutFirstByte is time from sending request to receiving first byte of response
+	TimeoutFirstByte TimeoutPhase = "first_byte"
+	// TimeoutBodyRead is reading whole response body
+	TimeoutBodyRead TimeoutPhase = "body_read"
+)
+
+// Timeouts limits each phase of api call, zero means no limit.
+// limits except BodyRead are applied to each attempt of retries.
+type Timeouts struct {
+	// Attempt limits each attempt until response header is received
+	Attempt time.Duration
+	// Connect limits dial and TLS handshake
+	Connect time.Duration
+	// FirstByte limits time from sending request to receiving first byte of response
+	FirstByte time.Duration
+	// BodyRead limits reading whole response body
+	BodyRead time.Duration
+}
+
+func (t Timeouts) isZero() bool {
+	return t == Timeouts{}
+}
+
+// TimeoutError is returned when timeout of the phase is expired.
+// errors.Is(err, context.DeadlineExceeded) is true.
+type TimeoutError struct {
+	Phase TimeoutPhase
+	// Limit is the expired timeout
+	Limit time.Duration
+	Err   error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timeout %s exceeded: %v", e.Phase, e.Limit, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports that error is timeout, same as net.Error
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// newTimeoutError replaces cancellation in err by context.DeadlineExceeded
+func newTimeoutError(phase TimeoutPhase, timeout time.Duration, err error) *TimeoutError {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = &url.Error{Op: urlErr.Op, URL: urlErr.URL, Err: context.DeadlineExceeded}
+	} else {
+		err = context.DeadlineExceeded
+	}
+	return &TimeoutError{Phase: phase, Limit: timeout, Err: err}
+}
+
+// phaseTimer cancels context when timeout of any phase is expired, and remembers the phase
+type phaseTimer struct {
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	timers   map[TimeoutPhase]*time.Timer
+	expired  TimeoutPhase
+	duration time.Duration
+}
+
+func newPhaseTimer(cancel context.CancelFunc) *phaseTimer {
+	return &phaseTimer{cancel: cancel, timers: map[TimeoutPhase]*time.Timer{}}
+}
+
+// start starts timer of phase, zero timeout is ignored
+func (p *phaseTimer) start(phase TimeoutPhase, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if timer, ok := p.timers[phase]; ok {
+		timer.Stop()
+	}
+	p.timers[phase] = time.AfterFunc(timeout, func() {
+		p.mu.Lock()
+		if p.expired == `` {
+			p.expired = phase
+			p.duration = timeout
+		}
+		p.mu.Unlock()
+		p.cancel()
+	})
+}
+
+// stop stops timers of phases
+func (p *phaseTimer) stop(phases ...TimeoutPhase) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, phase := range phases {
+		if timer, ok := p.timers[phase]; ok {
+			timer.Stop()
+			delete(p.timers, phase)
+		}
+	}
+}
+
+// stopAll stops all timers and releases context
+func (p *phaseTimer) stopAll() {
+	p.mu.Lock()
+	for phase, timer := range p.timers {
+		timer.Stop()
+		delete(p.timers, phase)
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// wrap returns TimeoutError if err is caused by expired timeout
+func (p *phaseTimer) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.expired == `` {
+		return err
+	}
+	return newTimeoutError(p.expired, p.duration, err)
+}
+
+// attempt sends req by do with limits of Timeouts.
+// response body keeps context until it is closed.
+func (cli *client) attempt(req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	timeouts := cli.timeouts
+	if timeouts.isZero() {
+		return do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := newPhaseTimer(cancel)
+	timer.start(TimeoutAttempt, timeouts.Attempt)
+	if timeouts.Connect > 0 || timeouts.FirstByte > 0 {
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GetConn: func(string) {
+				timer.start(TimeoutConnect, timeouts.Connect)
+			},
+			GotConn: func(httptrace.GotConnInfo) {
+				timer.stop(TimeoutConnect)
+			},
+			WroteRequest: func(httptrace.WroteRequestInfo) {
+				timer.start(TimeoutFirstByte, timeouts.FirstByte)
+			},
+			GotFirstResponseByte: func() {
+				timer.stop(TimeoutFirstByte)
+			},
+		})
+	}
+
+	res, err := do(req.WithContext(ctx))
+	timer.stop(TimeoutAttempt, TimeoutConnect, TimeoutFirstByte)
+	if err != nil {
+		timer.stopAll()
+		return nil, timer.wrap(err)
+	}
+	timer.start(TimeoutBodyRead, timeouts.BodyRead)
+	res.Body = &timeoutBody{body: res.Body, timer: timer}
+	return res, nil
+}
+
+// withTimeout sends req by doer within timeout set by Timeout.
+// response body keeps context until it is closed.
+func (cli *client) withTimeout(req *http.Request, doer Doer) (*http.Response, error) {
+	if cli.timeout <= 0 {
+		return doer.Do(req)
+	}
+
+	parent := req.Context()
+	// deadline is visible to retry
+	ctx, cancel := context.WithTimeout(parent, cli.timeout)
+	res, err := doer.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+			return nil, newTimeoutError(TimeoutTotal, cli.timeout, err)
+		}
+		return nil, err
+	}
+	res.Body = &timeoutBody{body: res.Body, timer: newPhaseTimer(cancel), total: cli.timeout, ctx: ctx, parent: parent}
+	return res, nil
+}
+
+// timeoutBody returns TimeoutError if reading body is stopped by timeout,
+// and releases context when it is closed.
+type timeoutBody struct {
+	body  io.ReadCloser
+	timer *phaseTimer
+	// total, ctx and parent detect timeout set by Timeout
+	total  time.Duration
+	ctx    context.Context
+	parent context.Context
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if err == nil {
+		return n, nil
+	}
+	if err == io.EOF {
+		b.timer.stop(TimeoutBodyRead)
+		return n, err
+	}
+	if b.ctx != nil && b.ctx.Err() == context.DeadlineExceeded && b.parent.Err() == nil {
+		return n, newTimeoutError(TimeoutTotal, b.total, err)
+	}
+	return n, b.timer.wrap(err)
+}
+
+func (b *timeoutBody) Close() error {
+	err := b.body.Close()
+	b.timer.stopAll()
+	return err
+}
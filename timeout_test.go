@@ -0,0 +1,141 @@
+package gorest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_client_Timeouts(t *testing.T) {
+	var requests int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow_header":
+			time.Sleep(200 * time.Millisecond)
+		case "/slow_body":
+			_, _ = w.Write([]byte("first"))
+			w.(http.Flusher).Flush()
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte("second"))
+		case "/slow_first_attempt":
+			if atomic.AddInt32(&requests, 1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	blockingDialClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		request   Executor
+		wantPhase TimeoutPhase
+	}{
+		{
+			name:      "total",
+			request:   Get(server.URL).Path("/slow_header").Client(httpClient).Timeout(50 * time.Millisecond),
+			wantPhase: TimeoutTotal,
+		},
+		{
+			name:      "total_body_read",
+			request:   Get(server.URL).Path("/slow_body").Client(httpClient).Timeout(50 * time.Millisecond),
+			wantPhase: TimeoutTotal,
+		},
+		{
+			name:      "connect",
+			request:   Get("http://example.com").Client(blockingDialClient).Timeouts(Timeouts{Connect: 50 * time.Millisecond}),
+			wantPhase: TimeoutConnect,
+		},
+		{
+			name: "first_byte",
+			request: Get(server.URL).Path("/slow_header").Client(httpClient).
+				Timeouts(Timeouts{Connect: time.Second, FirstByte: 50 * time.Millisecond}),
+			wantPhase: TimeoutFirstByte,
+		},
+		{
+			name:      "attempt",
+			request:   Get(server.URL).Path("/slow_header").Client(httpClient).Timeouts(Timeouts{Attempt: 50 * time.Millisecond}),
+			wantPhase: TimeoutAttempt,
+		},
+		{
+			name: "body_read",
+			request: Get(server.URL).Path("/slow_body").Client(httpClient).
+				Timeouts(Timeouts{FirstByte: time.Second, BodyRead: 50 * time.Millisecond}),
+			wantPhase: TimeoutBodyRead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.HandleBody(func(body []uint8) error {
+				return nil
+			})
+
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("error must be TimeoutError, got => %v", err)
+			}
+			if timeoutErr.Phase != tt.wantPhase || timeoutErr.Limit != 50*time.Millisecond {
+				t.Errorf("invalid timeout, got => %s %s", timeoutErr.Phase, timeoutErr.Limit)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error must be deadline exceeded, got => %v", err)
+			}
+			if errors.Is(err, context.Canceled) {
+				t.Errorf("error must not be canceled, got => %v", err)
+			}
+		})
+	}
+
+	t.Run("retry_after_attempt_timeout", func(t *testing.T) {
+		err := Get(server.URL).Path("/slow_first_attempt").Client(httpClient).
+			Timeout(time.Second).
+			Timeouts(Timeouts{Attempt: 50 * time.Millisecond}).
+			Retry(RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}).
+			HandleBody(func(body []uint8) error {
+				if string(body) != "success" {
+					t.Errorf("wrong response, got => %s", body)
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("second attempt must succeed, %s", err)
+		}
+		if got := atomic.LoadInt32(&requests); got != 2 {
+			t.Errorf("request must be sent twice, got => %d", got)
+		}
+	})
+}
+
+func TestDefaultRetryOnError_timeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "attempt", err: newTimeoutError(TimeoutAttempt, time.Second, context.Canceled), want: true},
+		{name: "first_byte", err: newTimeoutError(TimeoutFirstByte, time.Second, context.Canceled), want: true},
+		{name: "total", err: newTimeoutError(TimeoutTotal, time.Second, context.DeadlineExceeded), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryOnError(tt.err); got != tt.want {
+				t.Errorf("DefaultRetryOnError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}